@@ -5,16 +5,28 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/shellus/jhttp/internal/cli"
+	"github.com/shellus/jhttp/internal/cookies"
 	"github.com/shellus/jhttp/internal/environment"
 	"github.com/shellus/jhttp/internal/executor"
+	"github.com/shellus/jhttp/internal/format"
+	"github.com/shellus/jhttp/internal/models"
 	"github.com/shellus/jhttp/internal/parser"
+	"github.com/shellus/jhttp/internal/report"
+	"github.com/shellus/jhttp/internal/script"
 )
 
+// 退出码，多种失败同时出现时使用最严重的一种：网络错误 > HTTP状态错误 > 测试失败
 const (
-	exitSuccess = 0
-	exitFailure = 1
+	exitSuccess   = 0 // 全部成功
+	exitFailure   = 1 // 其他错误：参数错误、执行中断、写入文件失败等
+	exitParse     = 2 // .http文件解析错误
+	exitEnv       = 3 // 环境文件或.env文件错误
+	exitTransport = 4 // 请求没有完成：网络错误、超时、预请求脚本失败等
+	exitStatus    = 5 // 响应状态码匹配--fail-on-status
+	exitTest      = 6 // 测试或断言失败、响应处理脚本出错
 )
 
 func main() {
@@ -44,53 +56,308 @@ func main() {
 		os.Exit(exitSuccess)
 	}
 
+	// 清空或查看Cookie文件
+	if opts.ClearCookies || opts.ListCookies {
+		if opts.CookieJar == "" {
+			fmt.Fprintln(os.Stderr, "错误: --clear-cookies和--list-cookies需要同时指定--cookie-jar")
+			os.Exit(exitFailure)
+		}
+
+		jar := cookies.NewJar()
+		if opts.ClearCookies {
+			if err := jar.Save(opts.CookieJar); err != nil {
+				fmt.Fprintf(os.Stderr, "清空Cookie文件错误: %v\n", err)
+				os.Exit(exitFailure)
+			}
+			fmt.Printf("已清空Cookie文件: %s\n", opts.CookieJar)
+		} else {
+			if err := jar.Load(opts.CookieJar); err != nil {
+				fmt.Fprintf(os.Stderr, "加载Cookie文件错误: %v\n", err)
+				os.Exit(exitFailure)
+			}
+			printCookies(opts.CookieJar, jar.Entries())
+			os.Exit(exitSuccess)
+		}
+
+		if len(opts.HTTPFiles) == 0 {
+			os.Exit(exitSuccess)
+		}
+	}
+
 	// 检查是否提供了HTTP文件
-	if opts.HTTPFile == "" {
-		fmt.Fprintln(os.Stderr, "错误: 必须提供一个.http文件")
+	if len(opts.HTTPFiles) == 0 {
+		fmt.Fprintln(os.Stderr, "错误: 必须提供至少一个.http文件、目录或通配符")
 		cli.PrintUsage(os.Stderr, progName)
 		os.Exit(exitFailure)
 	}
 
-	// 解析HTTP文件
-	httpFile, err := parser.ParseFile(opts.HTTPFile)
+	// 展开目录和通配符，找不到或无法读取文件不是解析错误
+	paths, err := cli.ExpandInputs(opts.HTTPFiles)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "解析文件错误: %v\n", err)
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
 		os.Exit(exitFailure)
 	}
 
+	// 解析所有HTTP文件，任何一个文件解析失败都不执行请求
+	httpFiles := make([]*models.HTTPFile, 0, len(paths))
+	for _, path := range paths {
+		httpFile, err := parser.ParseFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "解析文件错误: %v\n", err)
+			os.Exit(exitParse)
+		}
+		httpFiles = append(httpFiles, httpFile)
+	}
+
 	// 列出请求并退出
 	if opts.ListRequests {
-		fmt.Printf("文件 '%s' 中的请求:\n", opts.HTTPFile)
-		if len(httpFile.Requests) == 0 {
-			fmt.Println("  没有找到请求")
-		} else {
-			for i, req := range httpFile.Requests {
-				displayName := req.Name
-				if displayName == "" {
-					displayName = fmt.Sprintf("%s %s", req.Method, req.URL)
-				}
-				fmt.Printf("%3d. %s\n", i+1, displayName)
-				if req.Description != "" {
-					// 对描述进行处理，确保多行描述缩进对齐
-					descLines := strings.Split(req.Description, "\n")
-					for _, line := range descLines {
-						fmt.Printf("     描述: %s\n", line)
-					}
-				}
+		for i, httpFile := range httpFiles {
+			if i > 0 {
+				fmt.Println()
 			}
+			listRequests(httpFile)
 		}
 		os.Exit(exitSuccess)
 	}
 
-	// 处理环境变量文件
+	// 选择要执行的请求，任何一项选择没有选中请求时报错；执行多个文件时只执行包含选中请求的文件
+	selector, err := executor.ParseSelector(opts.Requests, opts.Tags, opts.ExcludeTags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: --request: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if len(httpFiles) > 1 && selector.HasIndex() {
+		fmt.Fprintln(os.Stderr, "错误: --request: 执行多个文件时序号含义不明确，请使用\"文件:行号\"选择请求")
+		os.Exit(exitFailure)
+	}
+	if unmatched := selector.Unmatched(httpFiles...); len(unmatched) > 0 {
+		fmt.Fprintf(os.Stderr, "错误: 没有与 '%s' 匹配的请求，请使用--list查看请求名称、序号和行号\n", strings.Join(unmatched, "', '"))
+		os.Exit(exitFailure)
+	}
+	if selector != nil {
+		matched := make([]*models.HTTPFile, 0, len(httpFiles))
+		for _, httpFile := range httpFiles {
+			if len(selector.Select(httpFile)) > 0 {
+				matched = append(matched, httpFile)
+			}
+		}
+		if len(matched) == 0 {
+			fmt.Fprintf(os.Stderr, "错误: 没有与 '%s' 匹配的请求\n", selector)
+			os.Exit(exitFailure)
+		}
+		httpFiles = matched
+	}
+
+	// 为每个文件加载环境变量和.env文件，未指定--env-file时在每个文件所在目录中查找环境文件
+	for _, httpFile := range httpFiles {
+		if err := loadEnvironment(httpFile, opts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitEnv)
+		}
+	}
+
+	if opts.Format != cli.FormatText && (opts.Verbose || opts.Rate > 0 || opts.Stages != "") {
+		fmt.Fprintln(os.Stderr, "错误: --format json/ndjson 不能与--verbose或压测模式同时使用")
+		os.Exit(exitFailure)
+	}
+
+	// 输出到标准输出的报告会取代文本结果，因此最多只能有一个，且不能与JSON输出同时使用
+	stdoutReports := 0
+	for _, spec := range opts.Reports {
+		if spec.Path == "" {
+			stdoutReports++
+		}
+	}
+	if stdoutReports > 1 || (stdoutReports > 0 && (opts.Format != cli.FormatText || opts.Verbose)) {
+		fmt.Fprintln(os.Stderr, "错误: 最多只能有一个报告输出到标准输出，且不能与--format json/ndjson或--verbose同时使用")
+		os.Exit(exitFailure)
+	}
+
+	// 标准输出是机器可读的结果时，脚本的client.log输出到标准错误
+	if opts.Format != cli.FormatText || stdoutReports > 0 {
+		script.SetLogOutput(os.Stderr)
+	}
+
+	if opts.Concurrent < 1 || opts.Repeat < 1 || opts.Delay < 0 {
+		fmt.Fprintln(os.Stderr, "错误: --concurrent和--repeat必须大于0，--delay不能为负数")
+		os.Exit(exitFailure)
+	}
+
+	if (opts.Rate > 0 || opts.Stages != "") && len(httpFiles) > 1 {
+		fmt.Fprintln(os.Stderr, "错误: 压测模式只能指定一个.http文件")
+		os.Exit(exitFailure)
+	}
+
+	failOnStatus, err := executor.ParseStatusPatterns(opts.FailOnStatus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: --fail-on-status: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	// 创建执行器，所有文件共用一个执行器，Cookie在文件之间共享
+	exec := executor.NewExecutor(opts.Verbose)
+	exec.SetFailOnStatus(failOnStatus)
+	exec.SetFailFast(opts.FailFast)
+	exec.SetCompare(opts.Compare)
+	output := outputOptions(opts)
+	exec.SetOutputOptions(output)
+	exec.SetDelay(time.Duration(opts.Delay) * time.Millisecond)
+
+	// 加载持久化的Cookie
+	if opts.CookieJar != "" {
+		if err := exec.CookieJar().Load(opts.CookieJar); err != nil {
+			fmt.Fprintf(os.Stderr, "加载Cookie文件错误: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	}
+
+	// NDJSON格式在每个请求完成时输出一行结果
+	var ndjson *report.NDJSONWriter
+	if opts.Format == cli.FormatNDJSON {
+		ndjson = report.NewNDJSONWriter(os.Stdout, failOnStatus)
+		exec.SetResponseCallback(func(httpFile *models.HTTPFile, resp *models.HTTPResponse) {
+			ndjson.Write(httpFile.Path, resp)
+		})
+	}
+
+	// 压测模式：按目标速率发送请求，只输出进度和压测结果
+	if opts.Rate > 0 || opts.Stages != "" {
+		os.Exit(runLoad(exec, httpFiles[0], selector, failOnStatus, opts))
+	}
+
+	// 依次执行每个文件，一个文件执行出错时继续执行其他文件
+	multiple := len(httpFiles) > 1
+	textOutput := opts.Format == cli.FormatText && stdoutReports == 0
+	results := make([]*fileResult, 0, len(httpFiles))
+	responses := make([]*models.HTTPResponse, 0)
+	executeFailed := false
+	startTime := time.Now()
+	for _, httpFile := range httpFiles {
+		if multiple && (textOutput || opts.Verbose) {
+			fmt.Printf("\n########## 文件: %s ##########\n", httpFile.Path)
+		}
+
+		result := runFile(exec, httpFile, selector, opts)
+		results = append(results, result)
+		responses = append(responses, result.Responses...)
+
+		// 执行出错时仍然输出已执行的请求的结果，出错的请求记录为带有错误的结果
+		if textOutput {
+			printResponses(opts, result.Responses, selector != nil && len(selector.Select(httpFile)) == 1, output)
+		}
+		if result.Err != nil {
+			executeFailed = true
+			fmt.Fprintf(os.Stderr, "执行请求错误: %s: %v\n", httpFile.Path, result.Err)
+		}
+
+		// 启用--fail-fast时，一个文件失败后不再执行后续文件
+		if opts.FailFast && (result.Err != nil || executor.WorstFailure(result.Responses, failOnStatus) != executor.FailureNone) {
+			break
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	// 保存Cookie，即使执行中途出错也保留已收到的Cookie
+	if opts.CookieJar != "" {
+		if saveErr := exec.CookieJar().Save(opts.CookieJar); saveErr != nil {
+			fmt.Fprintf(os.Stderr, "保存Cookie文件错误: %v\n", saveErr)
+		}
+	}
+
+	// 输出执行结果：JSON格式输出所有结果，NDJSON格式已在执行过程中逐条输出；执行出错时也输出结果和报告，最后以退出码1退出
+	suites := make([]report.Suite, 0, len(results))
+	for _, result := range results {
+		suites = append(suites, report.Suite{File: result.File.Path, Responses: result.Responses, FailOnStatus: failOnStatus})
+	}
+	switch opts.Format {
+	case cli.FormatJSON:
+		if err := report.WriteJSON(os.Stdout, suites); err != nil {
+			fmt.Fprintf(os.Stderr, "输出JSON错误: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	case cli.FormatNDJSON:
+		if err := ndjson.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "输出NDJSON错误: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	default:
+		if textOutput {
+			printSummary(opts, results, responses, elapsed)
+		}
+	}
+
+	// 生成报告
+	for _, spec := range opts.Reports {
+		if err := writeReport(spec, suites); err != nil {
+			fmt.Fprintf(os.Stderr, "生成%s报告错误: %v\n", spec.Kind, err)
+			os.Exit(exitFailure)
+		}
+	}
+
+	// 如果指定了输出文件，将响应保存到文件
+	if opts.OutputFile != "" && len(responses) > 0 {
+		resp := responses[0]
+		if selector != nil {
+			resp = responses[len(responses)-1]
+		}
+		if err := os.WriteFile(opts.OutputFile, resp.Body, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "写入输出文件错误: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		if opts.Format == cli.FormatText {
+			fmt.Printf("响应已保存到文件: %s\n", opts.OutputFile)
+		}
+	}
+
+	if executeFailed {
+		os.Exit(exitFailure)
+	}
+	os.Exit(exitCode(executor.WorstFailure(responses, failOnStatus)))
+}
+
+// fileResult 一个.http文件的执行结果
+type fileResult struct {
+	File      *models.HTTPFile
+	Responses []*models.HTTPResponse
+	Stats     *models.Statistics
+	Elapsed   time.Duration
+	Err       error
+}
+
+// runFile 执行一个HTTP文件，并行模式或需要重复执行时使用工作池，未启用并行时并发数为1
+func runFile(exec *executor.Executor, httpFile *models.HTTPFile, selector *executor.Selector, opts *cli.Options) *fileResult {
+	result := &fileResult{File: httpFile}
+	startTime := time.Now()
+	if opts.Parallel || opts.Repeat > 1 {
+		concurrency := 1
+		if opts.Parallel {
+			concurrency = opts.Concurrent
+		}
+		result.Responses, result.Stats, result.Err = exec.ExecuteParallel(httpFile, executor.ParallelOptions{
+			Selector:    selector,
+			Env:         opts.Env,
+			Concurrency: concurrency,
+			Repeat:      opts.Repeat,
+		})
+		result.Elapsed = time.Since(startTime)
+	} else {
+		result.Responses, result.Err = exec.ExecuteFile(httpFile, selector, opts.Env)
+		result.Elapsed = time.Since(startTime)
+		result.Stats = executor.ComputeStatistics(result.Responses, result.Elapsed)
+	}
+	return result
+}
+
+// loadEnvironment 为HTTP文件加载环境变量和.env文件
+func loadEnvironment(httpFile *models.HTTPFile, opts *cli.Options) error {
 	var envVars map[string]string
+	var err error
 
 	if opts.EnvFile != "" && opts.Env != "" {
 		// 用户指定了环境文件路径和环境名称
 		envVars, err = environment.LoadEnvFile(opts.EnvFile, opts.Env)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "加载环境变量错误: %v\n", err)
-			os.Exit(exitFailure)
+			return fmt.Errorf("加载环境变量错误: %v", err)
 		}
 
 		if opts.Verbose {
@@ -98,25 +365,23 @@ func main() {
 		}
 	} else if opts.Env != "" {
 		// 用户只指定了环境名称，尝试自动查找环境文件
-		envFilePath, found, needWarning := environment.FindEnvFile(opts.HTTPFile)
-		if found {
-			envVars, err = environment.LoadEnvFile(envFilePath, opts.Env)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "加载环境变量错误: %v\n", err)
-				os.Exit(exitFailure)
-			}
+		envFilePath, found, needWarning := environment.FindEnvFile(httpFile.Path)
+		if !found {
+			return fmt.Errorf("错误: 未找到 '%s' 的环境文件，但指定了环境名称 '%s'\n"+
+				"请使用 --env-file 参数指定环境文件路径，或确保在.http文件所在目录或上级目录有环境文件", httpFile.Path, opts.Env)
+		}
 
-			if needWarning {
-				fmt.Fprintf(os.Stderr, "警告: 自动使用了上级目录中的环境文件 '%s'\n", envFilePath)
-			}
+		envVars, err = environment.LoadEnvFile(envFilePath, opts.Env)
+		if err != nil {
+			return fmt.Errorf("加载环境变量错误: %v", err)
+		}
 
-			if opts.Verbose {
-				fmt.Printf("已从 '%s' 加载环境 '%s' 中的 %d 个变量\n", envFilePath, opts.Env, len(envVars))
-			}
-		} else {
-			fmt.Fprintf(os.Stderr, "错误: 未找到环境文件，但指定了环境名称 '%s'\n", opts.Env)
-			fmt.Fprintln(os.Stderr, "请使用 --env-file 参数指定环境文件路径，或确保在.http文件所在目录或上级目录有环境文件")
-			os.Exit(exitFailure)
+		if needWarning {
+			fmt.Fprintf(os.Stderr, "警告: 自动使用了上级目录中的环境文件 '%s'\n", envFilePath)
+		}
+
+		if opts.Verbose {
+			fmt.Printf("已从 '%s' 加载环境 '%s' 中的 %d 个变量\n", envFilePath, opts.Env, len(envVars))
 		}
 	}
 
@@ -128,37 +393,247 @@ func main() {
 		httpFile.EnvironmentVars[opts.Env] = envVars
 	}
 
-	// 创建执行器
-	exec := executor.NewExecutor(opts.Verbose)
+	// 加载.env文件，供{{$dotenv NAME}}引用，格式错误的行只输出警告
+	if dotEnvPath, found, inParent := environment.FindDotEnvFile(httpFile.Path); found {
+		dotEnvVars, warnings, err := environment.LoadDotEnvFile(dotEnvPath)
+		if err != nil {
+			return fmt.Errorf("加载.env文件错误: %v", err)
+		}
+		httpFile.DotEnvVars = dotEnvVars
 
-	// 执行HTTP请求
-	responses, err := exec.ExecuteFile(httpFile, opts.RequestName, opts.Env)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "执行请求错误: %v\n", err)
-		os.Exit(exitFailure)
+		if inParent {
+			fmt.Fprintf(os.Stderr, "警告: 自动使用了上级目录中的.env文件 '%s'\n", dotEnvPath)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "警告: .env文件 '%s' %s\n", dotEnvPath, warning)
+		}
+		if opts.Verbose {
+			fmt.Printf("已从 '%s' 加载 %d 个.env变量\n", dotEnvPath, len(dotEnvVars))
+		}
 	}
+	return nil
+}
+
+// listRequests 列出文件中的请求
+func listRequests(httpFile *models.HTTPFile) {
+	fmt.Printf("文件 '%s' 中的请求:\n", httpFile.Path)
+	if len(httpFile.Requests) == 0 {
+		fmt.Println("  没有找到请求")
+		return
+	}
+	for i, req := range httpFile.Requests {
+		fmt.Printf("%3d. %s (第%d行)", i+1, req.DisplayName(), req.LineNumber)
+		if len(req.Tags) > 0 {
+			fmt.Printf(" [%s]", strings.Join(req.TagNames(), ", "))
+		}
+		fmt.Println()
+		if req.Description != "" {
+			// 对描述进行处理，确保多行描述缩进对齐
+			descLines := strings.Split(req.Description, "\n")
+			for _, line := range descLines {
+				fmt.Printf("     描述: %s\n", line)
+			}
+		}
+	}
+}
 
-	// 如果没有启用详细模式，但指定了单个请求，打印响应结果
-	if !opts.Verbose && opts.RequestName != "" && len(responses) > 0 {
-		executor.PrintResponse(responses[0])
+// exitCode 返回失败类型对应的退出码
+func exitCode(kind executor.FailureKind) int {
+	switch kind {
+	case executor.FailureTransport:
+		return exitTransport
+	case executor.FailureStatus:
+		return exitStatus
+	case executor.FailureAssertion:
+		return exitTest
+	}
+	return exitSuccess
+}
+
+// printResponses 以文本形式打印一个文件的执行结果，single表示只选中了一个请求
+func printResponses(opts *cli.Options, responses []*models.HTTPResponse, single bool, output format.Options) {
+	// 如果没有启用详细模式，但只选中了一个请求，打印响应结果（依赖请求的响应排在前面）
+	// 重复执行时响应数量较多，只打印统计信息
+	if !opts.Verbose && opts.Repeat > 1 {
+		fmt.Printf("成功执行 %d 个HTTP请求\n", len(responses))
+	} else if !opts.Verbose && single && len(responses) > 0 {
+		executor.PrintResponse(responses[len(responses)-1], output)
 	} else if !opts.Verbose {
 		fmt.Printf("成功执行 %d 个HTTP请求\n", len(responses))
 		for i, resp := range responses {
-			fmt.Printf("\n请求 #%d: %s\n", i+1, resp.Request.Name)
-			fmt.Printf("状态: %s\n", resp.Status)
+			fmt.Printf("\n请求 #%d: %s\n", i+1, resp.Request.DisplayName())
+			if resp.Error != nil {
+				fmt.Printf("错误: %v\n", resp.Error)
+			} else {
+				fmt.Printf("状态: %s\n", resp.Status)
+			}
 			fmt.Printf("耗时: %d ms\n", resp.Time)
+			if resp.OutputFile != "" {
+				fmt.Printf("已保存到: %s\n", resp.OutputFile)
+			}
+			if resp.ScriptError != nil {
+				fmt.Printf("脚本错误: %v\n", resp.ScriptError)
+			}
 		}
 	}
+}
 
-	// 如果指定了输出文件，将响应保存到文件
-	if opts.OutputFile != "" && len(responses) > 0 {
-		resp := responses[0]
-		if err := os.WriteFile(opts.OutputFile, resp.Body, 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "写入输出文件错误: %v\n", err)
-			os.Exit(exitFailure)
+// printSummary 打印所有文件的汇总、统计信息和测试汇总，执行多个文件时先打印每个文件的汇总
+func printSummary(opts *cli.Options, results []*fileResult, responses []*models.HTTPResponse, elapsed time.Duration) {
+	if len(results) > 1 {
+		printFileSummary(results)
+	}
+
+	if opts.Stats || opts.Repeat > 1 {
+		parts := make([]*models.Statistics, 0, len(results))
+		for _, result := range results {
+			if result.Stats != nil {
+				parts = append(parts, result.Stats)
+			}
+		}
+		if len(parts) == 1 {
+			executor.PrintStatistics(parts[0])
+		} else {
+			executor.PrintStatistics(executor.MergeStatistics(parts, responses, elapsed))
+		}
+	}
+
+	executor.PrintTestSummary(responses)
+}
+
+// printFileSummary 打印每个文件的请求数、失败数、测试数和耗时
+func printFileSummary(results []*fileResult) {
+	fmt.Println("\n文件汇总:")
+	totalRequests, totalFailed, totalTests, totalFailedTests := 0, 0, 0, 0
+	var totalElapsed time.Duration
+	for _, result := range results {
+		failed, tests, failedTests := 0, 0, 0
+		for _, resp := range result.Responses {
+			if resp.Error != nil || resp.StatusCode >= 400 {
+				failed++
+			}
+			tests += len(resp.Tests)
+			failedTests += resp.FailedTests()
+		}
+
+		status := "✓"
+		if result.Err != nil || failed > 0 || failedTests > 0 {
+			status = "✗"
+		}
+		fmt.Printf("  %s %s: 请求 %d 个，失败 %d 个，测试 %d 个，失败 %d 个，耗时 %d ms\n",
+			status, result.File.Path, len(result.Responses), failed, tests, failedTests, result.Elapsed.Milliseconds())
+		if result.Err != nil {
+			fmt.Printf("    执行错误: %v\n", result.Err)
+		}
+
+		totalRequests += len(result.Responses)
+		totalFailed += failed
+		totalTests += tests
+		totalFailedTests += failedTests
+		totalElapsed += result.Elapsed
+	}
+	fmt.Printf("  共 %d 个文件，请求 %d 个，失败 %d 个，测试 %d 个，失败 %d 个，耗时 %d ms\n",
+		len(results), totalRequests, totalFailed, totalTests, totalFailedTests, totalElapsed.Milliseconds())
+}
+
+// writeReport 生成报告，没有指定文件时输出到标准输出
+func writeReport(spec cli.ReportSpec, suites []report.Suite) error {
+	write := report.Writers[spec.Kind]
+	if spec.Path == "" {
+		return write(os.Stdout, suites)
+	}
+
+	file, err := os.Create(spec.Path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, suites); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// outputOptions 根据命令行选项确定响应体的输出格式
+// --no-color优先，其次是--color，都未指定时在终端中且没有设置NO_COLOR环境变量时使用颜色
+func outputOptions(opts *cli.Options) format.Options {
+	color := format.ColorSupported()
+	if opts.Color {
+		color = true
+	}
+	if opts.NoColor {
+		color = false
+	}
+	return format.Options{Pretty: opts.Pretty, Color: color}
+}
+
+// runLoad 执行压测并返回退出码
+func runLoad(exec *executor.Executor, httpFile *models.HTTPFile, selector *executor.Selector, failOnStatus executor.StatusPatterns, opts *cli.Options) int {
+	var stages []executor.LoadStage
+	if opts.Stages != "" {
+		var err error
+		stages, err = executor.ParseLoadStages(opts.Stages)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			return exitFailure
+		}
+	} else {
+		if opts.Duration <= 0 {
+			fmt.Fprintln(os.Stderr, "错误: 使用--rate时需要通过--duration指定持续时间")
+			return exitFailure
+		}
+		// 没有预热时以0时长的阶段立即切换到目标速率
+		stages = []executor.LoadStage{
+			{Duration: opts.RampUp, Target: opts.Rate},
+			{Duration: opts.Duration, Target: opts.Rate},
 		}
-		fmt.Printf("响应已保存到文件: %s\n", opts.OutputFile)
 	}
 
-	os.Exit(exitSuccess)
+	result, err := exec.ExecuteLoad(httpFile, executor.LoadOptions{
+		Selector:         selector,
+		Env:              opts.Env,
+		Stages:           stages,
+		MaxInFlight:      opts.MaxInFlight,
+		ProgressInterval: opts.Progress,
+	})
+
+	// 保存Cookie，即使执行中途出错也保留已收到的Cookie
+	if opts.CookieJar != "" {
+		if saveErr := exec.CookieJar().Save(opts.CookieJar); saveErr != nil {
+			fmt.Fprintf(os.Stderr, "保存Cookie文件错误: %v\n", saveErr)
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "执行压测错误: %v\n", err)
+		return exitFailure
+	}
+	executor.PrintLoadResult(result)
+
+	// 压测中没有收到响应的请求视为网络错误，状态码匹配--fail-on-status的请求视为HTTP状态错误
+	if len(result.Errors) > 0 {
+		return exitTransport
+	}
+	for code := range result.StatusCodes {
+		if failOnStatus.Match(code) {
+			return exitStatus
+		}
+	}
+	return exitSuccess
+}
+
+// printCookies 打印Cookie文件中的Cookie
+func printCookies(path string, entries []cookies.Entry) {
+	fmt.Printf("Cookie文件 '%s' 中的Cookie:\n", path)
+	if len(entries) == 0 {
+		fmt.Println("  没有Cookie")
+		return
+	}
+	for _, entry := range entries {
+		expires := "会话"
+		if !entry.Expires.IsZero() {
+			expires = entry.Expires.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("  %s%s  %s=%s  (过期: %s)\n", entry.Domain, entry.Path, entry.Name, entry.Value, expires)
+	}
 }
@@ -0,0 +1,225 @@
+package assertion
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/shellus/jhttp/internal/jsonpath"
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// 断言对象
+const (
+	SubjectStatus   = "status"   // 状态码
+	SubjectJSONPath = "jsonpath" // JSON响应体中的值
+	SubjectHeader   = "header"   // 响应头
+	SubjectTime     = "time"     // 请求耗时（毫秒）
+	SubjectBody     = "body"     // 响应体文本
+)
+
+// 支持的比较运算符
+var operators = map[string]bool{
+	"==":        true,
+	"!=":        true,
+	"<":         true,
+	"<=":        true,
+	">":         true,
+	">=":        true,
+	"contains":  true,
+	"!contains": true,
+	"matches":   true,
+	"exists":    true,
+	"!exists":   true,
+}
+
+// numberRegex 匹配十进制数字，不接受分数（1/2）和0x等进制前缀
+var numberRegex = regexp.MustCompile(`^[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?$`)
+
+// Parse 解析断言表达式，例如：
+//
+//	status == 200
+//	jsonpath $.code == 0
+//	header Content-Type contains json
+//	time < 500
+//	body contains success
+func Parse(expr string) (*models.Assertion, error) {
+	subject, rest := nextField(expr)
+	if subject == "" || strings.TrimSpace(rest) == "" {
+		return nil, fmt.Errorf("断言表达式不完整: %s", expr)
+	}
+
+	a := &models.Assertion{Expression: strings.TrimSpace(expr), Subject: strings.ToLower(subject)}
+	switch a.Subject {
+	case SubjectJSONPath, SubjectHeader:
+		a.Target, rest = nextField(rest)
+	case SubjectStatus, SubjectTime, SubjectBody:
+	default:
+		return nil, fmt.Errorf("未知的断言对象 '%s'", subject)
+	}
+
+	operator, rest := nextField(rest)
+	if operator == "" {
+		return nil, fmt.Errorf("断言缺少运算符: %s", expr)
+	}
+	a.Operator = strings.ToLower(operator)
+	if !operators[a.Operator] {
+		return nil, fmt.Errorf("未知的断言运算符 '%s'", operator)
+	}
+
+	// 期望值保留原文，引号中的连续空白不会被合并
+	expected := strings.TrimSpace(rest)
+	if a.Operator == "exists" || a.Operator == "!exists" {
+		if expected != "" {
+			return nil, fmt.Errorf("运算符 '%s' 不需要期望值: %s", a.Operator, expr)
+		}
+	} else if expected == "" {
+		return nil, fmt.Errorf("断言缺少期望值: %s", expr)
+	}
+	a.Expected = unquote(expected)
+
+	if a.Operator == "matches" {
+		if _, err := regexp.Compile(a.Expected); err != nil {
+			return nil, fmt.Errorf("无效的正则表达式 '%s': %w", a.Expected, err)
+		}
+	}
+
+	return a, nil
+}
+
+// Evaluate 对响应执行断言并返回测试结果
+func Evaluate(a *models.Assertion, resp *models.HTTPResponse) models.TestResult {
+	result := models.TestResult{Name: a.Expression}
+
+	actual, exists, err := actualValue(a, resp)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	switch a.Operator {
+	case "exists":
+		result.Passed = exists
+	case "!exists":
+		result.Passed = !exists
+	default:
+		result.Passed = exists && compare(actual, a.Operator, a.Expected)
+	}
+
+	switch {
+	case result.Passed:
+	case exists:
+		result.Message = fmt.Sprintf("%s 的实际值为 '%s'", describe(a), actual)
+	default:
+		result.Message = fmt.Sprintf("%s 不存在", describe(a))
+	}
+	return result
+}
+
+// actualValue 获取断言对象的实际值
+func actualValue(a *models.Assertion, resp *models.HTTPResponse) (string, bool, error) {
+	switch a.Subject {
+	case SubjectStatus:
+		return strconv.Itoa(resp.StatusCode), true, nil
+	case SubjectTime:
+		return strconv.FormatInt(resp.Time, 10), true, nil
+	case SubjectBody:
+		return resp.BodyString, true, nil
+	case SubjectHeader:
+		values := resp.Headers.Values(a.Target)
+		if len(values) == 0 {
+			return "", false, nil
+		}
+		return strings.Join(values, ", "), true, nil
+	case SubjectJSONPath:
+		value, err := jsonpath.Lookup(resp.Body, a.Target)
+		if err != nil {
+			if errors.Is(err, jsonpath.ErrNotFound) {
+				return "", false, nil
+			}
+			return "", false, err
+		}
+		return jsonpath.Stringify(value), true, nil
+	}
+	return "", false, fmt.Errorf("未知的断言对象 '%s'", a.Subject)
+}
+
+// nextField 返回字符串中的第一个以空白分隔的字段和之后未经处理的剩余部分
+func nextField(s string) (string, string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// parseNumber 将十进制数字解析为精确的有理数
+func parseNumber(s string) (*big.Rat, bool) {
+	if !numberRegex.MatchString(s) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// compare 比较实际值与期望值，两边都是数字时按数值精确比较，大整数不会丢失精度
+func compare(actual, operator, expected string) bool {
+	actualNum, okA := parseNumber(actual)
+	expectedNum, okE := parseNumber(expected)
+	numeric := okA && okE
+
+	switch operator {
+	case "==":
+		if numeric {
+			return actualNum.Cmp(expectedNum) == 0
+		}
+		return actual == expected
+	case "!=":
+		if numeric {
+			return actualNum.Cmp(expectedNum) != 0
+		}
+		return actual != expected
+	case "<", "<=", ">", ">=":
+		if !numeric {
+			return false
+		}
+		switch operator {
+		case "<":
+			return actualNum.Cmp(expectedNum) < 0
+		case "<=":
+			return actualNum.Cmp(expectedNum) <= 0
+		case ">":
+			return actualNum.Cmp(expectedNum) > 0
+		default:
+			return actualNum.Cmp(expectedNum) >= 0
+		}
+	case "contains":
+		return strings.Contains(actual, expected)
+	case "!contains":
+		return !strings.Contains(actual, expected)
+	case "matches":
+		matched, err := regexp.MatchString(expected, actual)
+		return err == nil && matched
+	}
+	return false
+}
+
+// describe 返回断言对象的描述文本
+func describe(a *models.Assertion) string {
+	if a.Target != "" {
+		return fmt.Sprintf("%s %s", a.Subject, a.Target)
+	}
+	return a.Subject
+}
+
+// unquote 去除期望值两侧的引号
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package assertion
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    models.Assertion
+		wantErr string
+	}{
+		{expr: "status == 200", want: models.Assertion{Subject: "status", Operator: "==", Expected: "200"}},
+		{expr: "  STATUS   !=   500 ", want: models.Assertion{Subject: "status", Operator: "!=", Expected: "500"}},
+		{expr: "jsonpath $.code == 0", want: models.Assertion{Subject: "jsonpath", Target: "$.code", Operator: "==", Expected: "0"}},
+		{expr: `jsonpath $.name == "张  三"`, want: models.Assertion{Subject: "jsonpath", Target: "$.name", Operator: "==", Expected: "张  三"}},
+		{expr: "body contains 'a\tb  c'", want: models.Assertion{Subject: "body", Operator: "contains", Expected: "a\tb  c"}},
+		{expr: "body contains hello   world", want: models.Assertion{Subject: "body", Operator: "contains", Expected: "hello   world"}},
+		{expr: "header Content-Type contains json", want: models.Assertion{Subject: "header", Target: "Content-Type", Operator: "contains", Expected: "json"}},
+		{expr: "header X-Trace exists", want: models.Assertion{Subject: "header", Target: "X-Trace", Operator: "exists"}},
+		{expr: "time < 500", want: models.Assertion{Subject: "time", Operator: "<", Expected: "500"}},
+		{expr: `body matches ^\{.*\}$`, want: models.Assertion{Subject: "body", Operator: "matches", Expected: `^\{.*\}$`}},
+		{expr: "status", wantErr: "断言表达式不完整"},
+		{expr: "cookie a == 1", wantErr: "未知的断言对象 'cookie'"},
+		{expr: "jsonpath $.code", wantErr: "断言缺少运算符"},
+		{expr: "status === 200", wantErr: "未知的断言运算符 '==='"},
+		{expr: "status ==", wantErr: "断言缺少期望值"},
+		{expr: "header X-Trace exists 1", wantErr: "不需要期望值"},
+		{expr: "body matches (", wantErr: "无效的正则表达式"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			a, err := Parse(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() 错误 = %v", err)
+			}
+			tt.want.Expression = strings.TrimSpace(tt.expr)
+			if *a != tt.want {
+				t.Errorf("Parse() = %+v, 期望 %+v", *a, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	resp := &models.HTTPResponse{
+		StatusCode: 201,
+		Headers: http.Header{
+			"Content-Type": []string{"application/json; charset=utf-8"},
+			"Set-Cookie":   []string{"a=1", "b=2"},
+			"X-Version":    []string{"0x10"},
+		},
+		Time: 120,
+	}
+	resp.BodyString = `{"code": 0, "id": 1234567890123456789, "price": 12.50, "name": "张  三", "ratio": "1/2", "tags": ["a"], "empty": null}`
+	resp.Body = []byte(resp.BodyString)
+
+	tests := []struct {
+		expr    string
+		passed  bool
+		message string
+	}{
+		{"status == 201", true, ""},
+		{"status != 200", true, ""},
+		{"status == 200", false, "status 的实际值为 '201'"},
+		{"status >= 200", true, ""},
+		{"status < 300", true, ""},
+		{"status > 201", false, "status 的实际值为 '201'"},
+		{"time <= 120", true, ""},
+		{"time < 100", false, "time 的实际值为 '120'"},
+		{"jsonpath $.code == 0", true, ""},
+		{"jsonpath $.code == 0.0", true, ""},
+		{"jsonpath $.price == 12.5", true, ""},
+		{"jsonpath $.price > 12.49", true, ""},
+		{"jsonpath $.id == 1234567890123456789", true, ""},
+		{"jsonpath $.id == 1234567890123456788", false, "jsonpath $.id 的实际值为 '1234567890123456789'"},
+		{"jsonpath $.id > 1234567890123456788", true, ""},
+		{`jsonpath $.name == "张  三"`, true, ""},
+		{"jsonpath $.name == 张 三", false, "jsonpath $.name 的实际值为 '张  三'"},
+		{`jsonpath $.ratio == "1/2"`, true, ""},
+		{"jsonpath $.ratio == 0.5", false, "jsonpath $.ratio 的实际值为 '1/2'"},
+		{"jsonpath $.ratio < 1", false, "jsonpath $.ratio 的实际值为 '1/2'"},
+		{`jsonpath $.tags == ["a"]`, true, ""},
+		{"jsonpath $.empty == null", true, ""},
+		{"jsonpath $.empty exists", true, ""},
+		{"jsonpath $.missing !exists", true, ""},
+		{"jsonpath $.missing == 1", false, "jsonpath $.missing 不存在"},
+		{"jsonpath $.missing exists", false, "jsonpath $.missing 不存在"},
+		{"jsonpath code == 0", false, "JSONPath必须以'$'开头"},
+		{"header content-type contains json", true, ""},
+		{"header Content-Type !contains xml", true, ""},
+		{"header Set-Cookie == a=1, b=2", true, ""},
+		{"header X-Version == 16", false, "header X-Version 的实际值为 '0x10'"},
+		{"header X-Trace exists", false, "header X-Trace 不存在"},
+		{"header X-Trace !exists", true, ""},
+		{"header X-Trace == 1", false, "header X-Trace 不存在"},
+		{`body matches "code":\s*0`, true, ""},
+		{"body matches ^<", false, "的实际值为"},
+		{"body contains 张  三", true, ""},
+		{"body < 10", false, "的实际值为"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			a, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() 错误 = %v", err)
+			}
+			result := Evaluate(a, resp)
+			if result.Name != tt.expr {
+				t.Errorf("Name = %q, 期望 %q", result.Name, tt.expr)
+			}
+			if result.Passed != tt.passed {
+				t.Errorf("Passed = %v, 期望 %v (%s)", result.Passed, tt.passed, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.message) || (tt.message == "") != (result.Message == "") {
+				t.Errorf("Message = %q, 期望包含 %q", result.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestEvaluateNotJSON(t *testing.T) {
+	a, err := Parse("jsonpath $.code == 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &models.HTTPResponse{StatusCode: 200, Headers: make(http.Header), Body: []byte("<html/>"), BodyString: "<html/>"}
+	result := Evaluate(a, resp)
+	if result.Passed || !strings.Contains(result.Message, "响应体不是有效的JSON") {
+		t.Errorf("Evaluate() = %+v", result)
+	}
+}
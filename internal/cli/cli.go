@@ -4,19 +4,102 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 )
 
+// 结果输出格式
+const (
+	FormatText   = "text"   // 文本（默认）
+	FormatJSON   = "json"   // 执行结束后输出JSON数组
+	FormatNDJSON = "ndjson" // 每个请求完成时输出一行JSON
+)
+
+// ReportKinds 支持的报告格式
+var ReportKinds = []string{"junit", "tap", "html"}
+
+// ReportSpec 表示一个--report参数，如junit=report.xml
+type ReportSpec struct {
+	Kind string // 报告格式
+	Path string // 输出文件，为空时输出到标准输出
+}
+
+// reportList 可重复指定的--report参数
+type reportList []ReportSpec
+
+// String 实现flag.Value
+func (l *reportList) String() string {
+	items := make([]string, 0, len(*l))
+	for _, spec := range *l {
+		items = append(items, spec.Kind+"="+spec.Path)
+	}
+	return strings.Join(items, ",")
+}
+
+// Set 实现flag.Value，解析"格式=文件"或"格式"
+func (l *reportList) Set(value string) error {
+	kind, path, _ := strings.Cut(value, "=")
+	kind = strings.TrimSpace(kind)
+	for _, known := range ReportKinds {
+		if kind == known {
+			*l = append(*l, ReportSpec{Kind: kind, Path: strings.TrimSpace(path)})
+			return nil
+		}
+	}
+	return fmt.Errorf("无效的报告格式 '%s'，支持: %s", kind, strings.Join(ReportKinds, "、"))
+}
+
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+// String 实现flag.Value
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set 实现flag.Value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 // Options 包含命令行解析后的选项
 type Options struct {
-	HTTPFile     string // HTTP文件路径
-	EnvFile      string // 环境变量文件
-	Env          string // 环境名称
-	RequestName  string // 请求名称（可选）
-	OutputFile   string // 输出文件（可选）
-	Verbose      bool   // 详细输出
-	ShowVersion  bool   // 显示版本信息
-	ShowHelp     bool   // 显示帮助信息
-	ListRequests bool   // 列出所有请求
+	HTTPFiles    []string     // HTTP文件、目录或通配符
+	EnvFile      string       // 环境变量文件
+	Env          string       // 环境名称
+	Requests     []string     // 要执行的请求：名称、序号、文件:行号、正则表达式或通配符（可选）
+	Tags         []string     // 只执行有这些标签的请求（可选）
+	ExcludeTags  []string     // 不执行有这些标签的请求（可选）
+	OutputFile   string       // 输出文件（可选）
+	Verbose      bool         // 详细输出
+	ShowVersion  bool         // 显示版本信息
+	ShowHelp     bool         // 显示帮助信息
+	ListRequests bool         // 列出所有请求
+	Compare      bool         // 与参考响应比较
+	CookieJar    string       // Cookie持久化文件（可选）
+	ClearCookies bool         // 清空Cookie文件
+	ListCookies  bool         // 列出Cookie文件中的Cookie
+	Parallel     bool         // 并行执行请求
+	Concurrent   int          // 并行执行时的最大并发数
+	Repeat       int          // 每个请求的重复次数
+	Stats        bool         // 显示统计信息
+	Delay        int          // 顺序执行时请求之间的间隔（毫秒）
+	Pretty       bool         // 美化响应体
+	NoPretty     bool         // 禁用美化
+	Color        bool         // 强制彩色输出
+	NoColor      bool         // 禁用彩色输出
+	Format       string       // 结果输出格式：text、json、ndjson
+	Reports      []ReportSpec // 要生成的报告
+	FailOnStatus string       // 视为失败的响应状态码，如"4xx,5xx"
+	FailFast     bool         // 遇到第一个失败时停止执行
+
+	Rate        float64       // 压测的目标速率（请求/秒）
+	Duration    time.Duration // 压测持续时间
+	RampUp      time.Duration // 压测的预热时间，速率从0线性增加到目标速率
+	Stages      string        // 压测阶段，如"30s:50,2m:50,10s:0"
+	MaxInFlight int           // 压测时最多同时进行的请求数
+	Progress    time.Duration // 压测进度输出间隔
 }
 
 // ParseArgs 解析命令行参数
@@ -30,12 +113,38 @@ func ParseArgs(args []string) (*Options, error) {
 	// 定义标志
 	fs.StringVar(&opts.EnvFile, "env-file", "", "指定环境变量文件路径")
 	fs.StringVar(&opts.Env, "env", "", "指定使用的环境名称")
-	fs.StringVar(&opts.RequestName, "request", "", "指定要执行的请求名称")
+	fs.Var((*stringList)(&opts.Requests), "request", "指定要执行的请求，可重复指定")
+	fs.Var((*stringList)(&opts.Tags), "tags", "只执行有这些标签的请求")
+	fs.Var((*stringList)(&opts.ExcludeTags), "exclude-tags", "不执行有这些标签的请求")
 	fs.StringVar(&opts.OutputFile, "output", "", "指定响应输出文件")
 	fs.BoolVar(&opts.Verbose, "verbose", false, "输出详细信息")
 	fs.BoolVar(&opts.ShowVersion, "version", false, "显示版本信息")
 	fs.BoolVar(&opts.ShowHelp, "help", false, "显示帮助信息")
 	fs.BoolVar(&opts.ListRequests, "list", false, "列出所有请求名称")
+	fs.BoolVar(&opts.Compare, "compare", false, "将响应与请求中引用的参考响应（<> 文件）比较")
+	fs.StringVar(&opts.CookieJar, "cookie-jar", "", "指定Cookie持久化文件")
+	fs.BoolVar(&opts.ClearCookies, "clear-cookies", false, "清空Cookie文件")
+	fs.BoolVar(&opts.ListCookies, "list-cookies", false, "列出Cookie文件中的Cookie")
+	fs.BoolVar(&opts.Parallel, "parallel", false, "启用并行模式执行请求")
+	fs.IntVar(&opts.Concurrent, "concurrent", 5, "设置并发数量")
+	fs.IntVar(&opts.Repeat, "repeat", 1, "设置每个请求的重复次数")
+	fs.BoolVar(&opts.Stats, "stats", false, "显示统计信息")
+	fs.IntVar(&opts.Delay, "delay", 200, "顺序执行时请求之间的间隔（毫秒）")
+	fs.BoolVar(&opts.Pretty, "pretty", true, "美化输出")
+	fs.BoolVar(&opts.NoPretty, "no-pretty", false, "禁用美化输出")
+	fs.BoolVar(&opts.Color, "color", false, "彩色输出")
+	fs.BoolVar(&opts.NoColor, "no-color", false, "禁用彩色输出")
+	fs.StringVar(&opts.Format, "format", FormatText, "结果输出格式")
+	var reports reportList
+	fs.Var(&reports, "report", "生成报告，如junit=report.xml，可重复指定")
+	fs.StringVar(&opts.FailOnStatus, "fail-on-status", "4xx,5xx", "视为失败的响应状态码")
+	fs.BoolVar(&opts.FailFast, "fail-fast", false, "遇到第一个失败时停止执行")
+	fs.Float64Var(&opts.Rate, "rate", 0, "压测的目标速率（请求/秒）")
+	fs.DurationVar(&opts.Duration, "duration", 0, "压测持续时间")
+	fs.DurationVar(&opts.RampUp, "ramp-up", 0, "压测的预热时间")
+	fs.StringVar(&opts.Stages, "stages", "", "压测阶段")
+	fs.IntVar(&opts.MaxInFlight, "max-in-flight", 0, "压测时最多同时进行的请求数")
+	fs.DurationVar(&opts.Progress, "progress", 5*time.Second, "压测进度输出间隔")
 
 	// 解析参数
 	if err := fs.Parse(args); err != nil {
@@ -43,9 +152,17 @@ func ParseArgs(args []string) (*Options, error) {
 	}
 
 	// 获取剩余的位置参数
-	remaining := fs.Args()
-	if len(remaining) > 0 {
-		opts.HTTPFile = remaining[0]
+	opts.HTTPFiles = fs.Args()
+
+	if opts.NoPretty {
+		opts.Pretty = false
+	}
+	opts.Reports = reports
+
+	switch opts.Format {
+	case FormatText, FormatJSON, FormatNDJSON:
+	default:
+		return nil, fmt.Errorf("无效的输出格式 '%s'，支持: text、json、ndjson", opts.Format)
 	}
 
 	return opts, nil
@@ -53,26 +170,73 @@ func ParseArgs(args []string) (*Options, error) {
 
 // PrintUsage 打印使用说明
 func PrintUsage(w io.Writer, progName string) {
-	fmt.Fprintf(w, "用法: %s [选项] <http-file>\n\n", progName)
+	fmt.Fprintf(w, "用法: %s [选项] <http-file|目录|通配符>...\n\n", progName)
 	fmt.Fprintf(w, "描述:\n")
-	fmt.Fprintf(w, "  %s 是一个命令行工具，用于执行IntelliJ IDEA格式的.http文件。\n\n", progName)
+	fmt.Fprintf(w, "  %s 是一个命令行工具，用于执行IntelliJ IDEA格式的.http文件。\n", progName)
+	fmt.Fprintf(w, "  可以指定多个文件、目录（递归查找*.http和*.rest文件）或通配符（支持**），作为一个整体执行。\n\n")
 	fmt.Fprintf(w, "选项:\n")
 	fmt.Fprintf(w, "  --env-file <file>     指定环境变量文件路径\n")
 	fmt.Fprintf(w, "  --env <n>          指定使用的环境名称（支持自动查找环境文件）\n")
-	fmt.Fprintf(w, "  --request <n>      指定要执行的请求名称\n")
+	fmt.Fprintf(w, "  --request <选择>      指定要执行的请求，可重复指定或用逗号分隔：名称、--list中的序号、\n")
+	fmt.Fprintf(w, "                        文件:行号、/正则表达式/、通配符（如\"获取*\"）；执行多个文件时不能使用序号\n")
+	fmt.Fprintf(w, "  --tags <标签>         只执行至少有其中一个标签（# @tag）的请求，用逗号分隔\n")
+	fmt.Fprintf(w, "  --exclude-tags <标签> 不执行有其中任意一个标签的请求，用逗号分隔\n")
 	fmt.Fprintf(w, "  --output <file>       指定响应输出文件\n")
 	fmt.Fprintf(w, "  --verbose             输出详细信息\n")
 	fmt.Fprintf(w, "  --version             显示版本信息\n")
 	fmt.Fprintf(w, "  --help                显示帮助信息\n")
-	fmt.Fprintf(w, "  --list                列出所有请求名称\n\n")
+	fmt.Fprintf(w, "  --list                列出所有请求名称\n")
+	fmt.Fprintf(w, "  --pretty              美化输出，缩进JSON和XML响应体（默认开启）\n")
+	fmt.Fprintf(w, "  --no-pretty           禁用美化输出，原样输出响应体\n")
+	fmt.Fprintf(w, "  --color               彩色输出（默认在终端中开启，设置NO_COLOR环境变量时关闭）\n")
+	fmt.Fprintf(w, "  --no-color            禁用彩色输出\n")
+	fmt.Fprintf(w, "  --format <格式>       结果输出格式：text（默认）、json、ndjson（每个请求完成时输出一行）\n")
+	fmt.Fprintf(w, "  --report <格式>[=文件] 生成报告，格式为junit、tap或html，可重复指定；不指定文件时输出到标准输出\n")
+	fmt.Fprintf(w, "  --fail-on-status <码> 将匹配的响应状态码视为失败，如404、500-599（默认4xx,5xx，none表示不检查状态码）\n")
+	fmt.Fprintf(w, "  --fail-fast           遇到第一个失败（网络错误、匹配的状态码、测试失败）时停止执行\n")
+	fmt.Fprintf(w, "  --compare             将响应与请求中引用的参考响应（<> 文件）比较\n")
+	fmt.Fprintf(w, "  --cookie-jar <file>   指定Cookie持久化文件，执行前加载，执行后保存\n")
+	fmt.Fprintf(w, "  --clear-cookies       清空Cookie文件（需要--cookie-jar）\n")
+	fmt.Fprintf(w, "  --list-cookies        列出Cookie文件中的Cookie（需要--cookie-jar）\n")
+	fmt.Fprintf(w, "  --parallel            启用并行模式执行请求，有依赖关系的请求仍按顺序执行\n")
+	fmt.Fprintf(w, "  --concurrent <n>      设置并行模式的并发数量（默认5）\n")
+	fmt.Fprintf(w, "  --repeat <n>          设置每个请求的重复次数（默认1），前置请求只执行一次\n")
+	fmt.Fprintf(w, "  --stats               显示统计信息（成功率、吞吐量、响应时间分布）\n")
+	fmt.Fprintf(w, "  --delay <ms>          顺序执行时请求之间的间隔毫秒数（默认200，0表示不等待）\n")
+	fmt.Fprintf(w, "  --rate <n>            压测：目标速率（请求/秒），需要同时指定--duration\n")
+	fmt.Fprintf(w, "  --duration <时间>     压测：以目标速率持续的时间，如2m\n")
+	fmt.Fprintf(w, "  --ramp-up <时间>      压测：在持续时间之前，速率从0线性增加到目标速率的时间\n")
+	fmt.Fprintf(w, "  --stages <阶段>       压测：自定义阶段，如\"30s:50,2m:50,10s:0\"（持续时间:目标速率）\n")
+	fmt.Fprintf(w, "  --max-in-flight <n>   压测：最多同时进行的请求数（默认不限制）\n")
+	fmt.Fprintf(w, "  --progress <时间>     压测：进度输出间隔（默认5s，0表示不输出）\n\n")
 	fmt.Fprintf(w, "请求名称格式说明:\n")
 	fmt.Fprintf(w, "  请求名称以'###'开头定义，例如：### 获取用户信息\n")
 	fmt.Fprintf(w, "  紧随其后的注释行（以'#'开头）会被保存为请求的描述，而不会成为请求名称的一部分\n")
-	fmt.Fprintf(w, "  使用--request参数时，需要使用完整的请求名（不包含注释内容）\n")
+	fmt.Fprintf(w, "  使用--request参数按名称选择时，需要使用完整的请求名（不包含注释内容）\n")
+	fmt.Fprintf(w, "  有同名请求时可以使用--list中显示的序号或行号选择其中一个\n")
 	fmt.Fprintf(w, "  如遇到请求无法匹配的情况，请使用--list选项查看实际的请求名称\n\n")
+	fmt.Fprintf(w, "退出码:\n")
+	fmt.Fprintf(w, "  0  全部成功\n")
+	fmt.Fprintf(w, "  1  其他错误（参数错误、执行中断、写入文件失败等）\n")
+	fmt.Fprintf(w, "  2  .http文件解析错误\n")
+	fmt.Fprintf(w, "  3  环境文件或.env文件错误\n")
+	fmt.Fprintf(w, "  4  请求没有完成（网络错误、超时、预请求脚本失败等）\n")
+	fmt.Fprintf(w, "  5  响应状态码匹配--fail-on-status\n")
+	fmt.Fprintf(w, "  6  测试或断言失败、响应处理脚本出错\n")
+	fmt.Fprintf(w, "  多种失败同时出现时使用最严重的一种，严重程度为 4 > 5 > 6\n\n")
 	fmt.Fprintf(w, "示例:\n")
 	fmt.Fprintf(w, "  %s example.http\n", progName)
 	fmt.Fprintf(w, "  %s --env 开发环境 example.http           # 自动查找环境文件\n", progName)
 	fmt.Fprintf(w, "  %s --env-file env.json --env 开发环境 example.http\n", progName)
 	fmt.Fprintf(w, "  %s --request \"获取用户信息\" example.http\n", progName)
+	fmt.Fprintf(w, "  %s --request 2,5 --request '/^获取.*图片$/' example.http\n", progName)
+	fmt.Fprintf(w, "  %s --request example.http:42 example.http\n", progName)
+	fmt.Fprintf(w, "  %s --tags smoke --exclude-tags slow tests/\n", progName)
+	fmt.Fprintf(w, "  %s --env 开发环境 api/ 'tests/**/*.http'   # 执行多个文件\n", progName)
+	fmt.Fprintf(w, "  %s --cookie-jar http-client.cookies example.http\n", progName)
+	fmt.Fprintf(w, "  %s --format ndjson example.http | jq .name\n", progName)
+	fmt.Fprintf(w, "  %s --report junit=report.xml --report tap=report.tap example.http\n", progName)
+	fmt.Fprintf(w, "  %s --fail-on-status 5xx --fail-fast example.http\n", progName)
+	fmt.Fprintf(w, "  %s --parallel --concurrent 10 --repeat 100 --stats example.http\n", progName)
+	fmt.Fprintf(w, "  %s --request \"获取用户信息\" --rate 50 --duration 2m --ramp-up 30s example.http\n", progName)
 }
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// httpFileExtensions 在目录中查找的文件扩展名
+var httpFileExtensions = []string{".http", ".rest"}
+
+// ExpandInputs 将命令行中的文件、目录和通配符展开为.http文件列表
+// 目录会递归查找*.http和*.rest文件（跳过以.开头的目录），通配符支持*、?、[...]和匹配任意层目录的**。
+// 结果按命令行顺序排列，同一个参数展开的文件按路径排序，重复的文件只保留第一次出现
+func ExpandInputs(inputs []string) ([]string, error) {
+	files := make([]string, 0, len(inputs))
+	seen := make(map[string]bool)
+	add := func(path string) {
+		key := filepath.Clean(path)
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
+		if !seen[key] {
+			seen[key] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, input := range inputs {
+		// 存在的路径即使包含[等字符也按普通路径处理
+		info, err := os.Stat(input)
+		if err != nil && isGlob(input) {
+			matches, err := expandGlob(input)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("没有与 '%s' 匹配的文件", input)
+			}
+			for _, match := range matches {
+				add(match)
+			}
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("无法访问 '%s': %w", input, err)
+		}
+		if !info.IsDir() {
+			add(input)
+			continue
+		}
+
+		found, err := findHTTPFiles(input)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("目录 '%s' 中没有.http或.rest文件", input)
+		}
+		for _, path := range found {
+			add(path)
+		}
+	}
+	return files, nil
+}
+
+// findHTTPFiles 递归查找目录中的.http和.rest文件
+func findHTTPFiles(dir string) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isHTTPFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查找目录 '%s' 中的文件失败: %w", dir, err)
+	}
+	return files, nil
+}
+
+// isHTTPFile 判断文件扩展名是否为.http或.rest
+func isHTTPFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, known := range httpFileExtensions {
+		if ext == known {
+			return true
+		}
+	}
+	return false
+}
+
+// isGlob 判断参数是否包含通配符
+func isGlob(input string) bool {
+	return strings.ContainsAny(input, "*?[")
+}
+
+// expandGlob 展开通配符：从通配符之前的目录开始遍历，返回匹配的.http和.rest文件
+// 匹配目录时返回目录中的全部.http和.rest文件
+func expandGlob(pattern string) ([]string, error) {
+	matcher, err := globRegexp(filepath.ToSlash(pattern))
+	if err != nil {
+		return nil, fmt.Errorf("无效的通配符 '%s': %w", pattern, err)
+	}
+
+	root := globRoot(pattern)
+	matches := make([]string, 0)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return matches, nil
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !matcher.MatchString(filepath.ToSlash(path)) {
+			return nil
+		}
+
+		if d.IsDir() {
+			found, err := findHTTPFiles(path)
+			if err != nil {
+				return err
+			}
+			matches = append(matches, found...)
+			return filepath.SkipDir
+		}
+		if isHTTPFile(path) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("展开通配符 '%s' 失败: %w", pattern, err)
+	}
+	return matches, nil
+}
+
+// globRoot 返回通配符中第一个包含通配符的路径部分之前的目录
+func globRoot(pattern string) string {
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	for i, part := range parts {
+		if isGlob(part) {
+			if i == 0 {
+				return "."
+			}
+			root := strings.Join(parts[:i], "/")
+			if root == "" {
+				root = "/"
+			}
+			return filepath.FromSlash(root)
+		}
+	}
+	return filepath.FromSlash(pattern)
+}
+
+// globRegexp 将通配符转换为正则表达式：**匹配任意层目录，*和?不匹配路径分隔符
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	var b strings.Builder
+	b.WriteString("^(?:\\./)?")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				// "**/"可以匹配零层目录
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("缺少 ']'")
+			}
+			class := string(runes[i+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
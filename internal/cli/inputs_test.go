@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		match    []string
+		notMatch []string
+	}{
+		{"*.http", []string{"a.http", "./a.http", "用户.http"}, []string{"dir/a.http", "a.rest"}},
+		{"api/*.http", []string{"api/a.http", "./api/a.http"}, []string{"api/v1/a.http", "a.http"}},
+		{"api/**/*.http", []string{"api/a.http", "api/v1/a.http", "api/v1/v2/a.http"}, []string{"a.http", "other/a.http"}},
+		{"**", []string{"a.http", "a/b/c.http"}, nil},
+		{"a?.http", []string{"ab.http", "a用.http"}, []string{"a.http", "abc.http", "a/.http"}},
+		{"接口/用户?.http", []string{"接口/用户1.http", "接口/用户甲.http"}, []string{"接口/用户.http", "接口/用户12.http"}},
+		{"[ab].http", []string{"a.http", "b.http"}, []string{"c.http", "ab.http"}},
+		{"[!ab].http", []string{"c.http"}, []string{"a.http"}},
+		{"[甲乙].http", []string{"甲.http"}, []string{"丙.http"}},
+		{"a+b(1).http", []string{"a+b(1).http"}, []string{"aab1.http"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := globRegexp(tt.pattern)
+			if err != nil {
+				t.Fatalf("globRegexp() 错误 = %v", err)
+			}
+			for _, path := range tt.match {
+				if !re.MatchString(path) {
+					t.Errorf("%q 应该匹配 %q (%s)", tt.pattern, path, re)
+				}
+			}
+			for _, path := range tt.notMatch {
+				if re.MatchString(path) {
+					t.Errorf("%q 不应该匹配 %q (%s)", tt.pattern, path, re)
+				}
+			}
+		})
+	}
+
+	if _, err := globRegexp("[ab.http"); err == nil {
+		t.Error("globRegexp(\"[ab.http\") 应该返回错误")
+	}
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.http",
+		"b.rest",
+		"c.json",
+		"api/用户.http",
+		"api/v1/order.http",
+		"api/.hidden/secret.http",
+		"literal[1].http",
+		"empty/readme.md",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	tests := []struct {
+		name    string
+		inputs  []string
+		want    []string
+		wantErr string
+	}{
+		{"文件", []string{p("a.http")}, []string{p("a.http")}, ""},
+		{"任意扩展名的文件", []string{p("c.json")}, []string{p("c.json")}, ""},
+		{"目录跳过隐藏目录", []string{p("api")}, []string{p("api/v1/order.http"), p("api/用户.http")}, ""},
+		{"通配符只匹配.http和.rest", []string{p("*.*")}, []string{p("a.http"), p("b.rest"), p("literal[1].http")}, ""},
+		{"匹配目录的通配符", []string{p("a*")}, []string{p("a.http"), p("api/v1/order.http"), p("api/用户.http")}, ""},
+		{"**", []string{p("**/*.http")}, []string{p("a.http"), p("api/v1/order.http"), p("api/用户.http"), p("literal[1].http")}, ""},
+		{"包含[的文件名", []string{p("literal[1].http")}, []string{p("literal[1].http")}, ""},
+		{"去除重复", []string{p("a.http"), p("*.http"), p("./a.http")}, []string{p("a.http"), p("literal[1].http")}, ""},
+		{"按参数顺序", []string{p("b.rest"), p("a.http")}, []string{p("b.rest"), p("a.http")}, ""},
+		{"没有匹配的通配符", []string{p("*.txt")}, nil, "没有与"},
+		{"不存在的通配符目录", []string{p("missing/*.http")}, nil, "没有与"},
+		{"不存在的文件", []string{p("missing.http")}, nil, "无法访问"},
+		{"没有.http文件的目录", []string{p("empty")}, nil, "没有.http或.rest文件"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandInputs(tt.inputs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExpandInputs() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandInputs() 错误 = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandInputs() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package compare
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/shellus/jhttp/internal/jsonpath"
+)
+
+// Diff 比较参考响应与实际响应，返回差异描述，没有差异时返回空切片
+// 两者都是JSON时按结构比较（忽略对象键的顺序），否则按行比较文本
+func Diff(expected, actual []byte) []string {
+	expectedJSON, errE := jsonpath.Decode(expected)
+	actualJSON, errA := jsonpath.Decode(actual)
+	if errE == nil && errA == nil {
+		diffs := make([]string, 0)
+		diffJSON("$", expectedJSON, actualJSON, &diffs)
+		return diffs
+	}
+	if isBinary(expected) || isBinary(actual) {
+		if bytes.Equal(expected, actual) {
+			return []string{}
+		}
+		return []string{fmt.Sprintf("二进制内容不同（期望 %d 字节，实际 %d 字节）", len(expected), len(actual))}
+	}
+	return diffText(string(expected), string(actual))
+}
+
+// diffJSON 递归比较两个JSON值
+func diffJSON(path string, expected, actual interface{}, diffs *[]string) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望对象，实际为 %s", path, jsonpath.Stringify(actual)))
+			return
+		}
+
+		keys := make([]string, 0, len(e)+len(a))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		for key := range a {
+			if _, ok := e[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := path + "." + key
+			ev, inExpected := e[key]
+			av, inActual := a[key]
+			switch {
+			case !inActual:
+				*diffs = append(*diffs, fmt.Sprintf("%s: 缺少字段（期望 %s）", childPath, jsonpath.Stringify(ev)))
+			case !inExpected:
+				*diffs = append(*diffs, fmt.Sprintf("%s: 多出字段（实际 %s）", childPath, jsonpath.Stringify(av)))
+			default:
+				diffJSON(childPath, ev, av, diffs)
+			}
+		}
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望数组，实际为 %s", path, jsonpath.Stringify(actual)))
+			return
+		}
+		if len(e) != len(a) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 数组长度不同（期望 %d，实际 %d）", path, len(e), len(a)))
+		}
+		for i := 0; i < len(e) && i < len(a); i++ {
+			diffJSON(fmt.Sprintf("%s[%d]", path, i), e[i], a[i], diffs)
+		}
+
+	case json.Number:
+		// 数字按数值精确比较，1.0与1相同，超过2^53的整数不会因为精度丢失被当作相同
+		a, ok := actual.(json.Number)
+		if !ok || !numbersEqual(e, a) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望 %s，实际 %s", path, jsonpath.Stringify(expected), jsonpath.Stringify(actual)))
+		}
+
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望 %s，实际 %s", path, jsonpath.Stringify(expected), jsonpath.Stringify(actual)))
+		}
+	}
+}
+
+// numbersEqual 精确比较两个JSON数字
+func numbersEqual(a, b json.Number) bool {
+	x, okX := new(big.Rat).SetString(a.String())
+	y, okY := new(big.Rat).SetString(b.String())
+	if !okX || !okY {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
+
+// diffText 按行比较文本
+func diffText(expected, actual string) []string {
+	if expected == actual {
+		return []string{}
+	}
+	if strings.TrimSpace(expected) == strings.TrimSpace(actual) {
+		return []string{}
+	}
+
+	expectedLines := strings.Split(strings.TrimRight(expected, "\n"), "\n")
+	actualLines := strings.Split(strings.TrimRight(actual, "\n"), "\n")
+
+	diffs := make([]string, 0)
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		switch {
+		case i >= len(actualLines):
+			diffs = append(diffs, fmt.Sprintf("第 %d 行: 缺少 %q", i+1, expectedLines[i]))
+		case i >= len(expectedLines):
+			diffs = append(diffs, fmt.Sprintf("第 %d 行: 多出 %q", i+1, actualLines[i]))
+		case expectedLines[i] != actualLines[i]:
+			diffs = append(diffs, fmt.Sprintf("第 %d 行: 期望 %q，实际 %q", i+1, expectedLines[i], actualLines[i]))
+		}
+	}
+	return diffs
+}
+
+// isBinary 判断数据是否为二进制内容
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0
+}
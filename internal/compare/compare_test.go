@@ -0,0 +1,36 @@
+package compare
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     []string // 每条差异应包含的内容，nil表示没有差异
+	}{
+		{"相同的JSON", `{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1}`, nil},
+		{"数字写法不同", `{"price": 12.5}`, `{"price": 12.50}`, nil},
+		{"大整数", `{"id": 1234567890123456789}`, `{"id": 1234567890123456788}`, []string{"$.id"}},
+		{"大整数相同", `{"id": 1234567890123456789}`, `{"id": 1234567890123456789}`, nil},
+		{"值不同", `{"a": {"b": "x"}}`, `{"a": {"b": "y"}}`, []string{"$.a.b"}},
+		{"文本", "第一行\n第二行", "第一行\n第三行", []string{"第二行"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := Diff([]byte(tt.expected), []byte(tt.actual))
+			if len(diffs) != len(tt.want) {
+				t.Fatalf("Diff() = %q, 期望 %d 条差异", diffs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(diffs[i], want) {
+					t.Errorf("Diff()[%d] = %q, 期望包含 %q", i, diffs[i], want)
+				}
+			}
+		})
+	}
+}
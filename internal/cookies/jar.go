@@ -0,0 +1,278 @@
+package cookies
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 持久化文件的表头，格式与IntelliJ的http-client.cookies相同
+const fileHeader = "# domain\tpath\tname\tvalue\tdate"
+
+// 会话Cookie（没有过期时间）在文件中的日期
+const sessionDate = "-1"
+
+// Entry 表示Cookie存储中的一条记录
+type Entry struct {
+	Domain  string    // 域名，以.开头表示对子域名也有效
+	Path    string    // 路径
+	Name    string    // 名称
+	Value   string    // 值
+	Expires time.Time // 过期时间，零值表示会话Cookie
+	Secure  bool      // 是否只在HTTPS中发送
+}
+
+// Jar Cookie存储，在标准库cookiejar的基础上记录所有Cookie以便保存到文件
+type Jar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	entries map[string]*Entry
+}
+
+// NewJar 创建一个空的Cookie存储
+func NewJar() *Jar {
+	jar, _ := cookiejar.New(nil)
+	return &Jar{
+		jar:     jar,
+		entries: make(map[string]*Entry),
+	}
+}
+
+// SetCookies 实现http.CookieJar接口
+// 只记录标准库cookiejar接受的Cookie，被拒绝的Cookie（如Domain与请求的主机不匹配）不会保存到文件
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return
+	}
+
+	now := time.Now()
+	for _, c := range cookies {
+		domain, ok := cookieDomain(host, c.Domain)
+		if !ok {
+			continue
+		}
+		entry := &Entry{
+			Domain: domain,
+			Path:   c.Path,
+			Name:   c.Name,
+			Value:  c.Value,
+			Secure: c.Secure,
+		}
+		if entry.Path == "" || !strings.HasPrefix(entry.Path, "/") {
+			entry.Path = defaultPath(u.Path)
+		}
+
+		switch {
+		case c.MaxAge > 0:
+			entry.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case c.MaxAge < 0:
+			delete(j.entries, entry.key())
+			continue
+		case !c.Expires.IsZero():
+			entry.Expires = c.Expires
+		}
+
+		if entry.expired(now) {
+			delete(j.entries, entry.key())
+			continue
+		}
+		j.entries[entry.key()] = entry
+	}
+}
+
+// Cookies 实现http.CookieJar接口
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// Entries 返回所有未过期的Cookie，按域名、路径、名称排序
+func (j *Jar) Entries() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]Entry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		if !entry.expired(now) {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].key() < entries[b].key()
+	})
+	return entries
+}
+
+// Clear 清空所有Cookie
+func (j *Jar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar, _ = cookiejar.New(nil)
+	j.entries = make(map[string]*Entry)
+}
+
+// Load 从文件加载Cookie，文件不存在时不做任何处理
+func (j *Jar) Load(filePath string) error {
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("无法读取Cookie文件: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 5 {
+			return fmt.Errorf("Cookie文件第 %d 行格式错误", lineNum)
+		}
+
+		entry := &Entry{
+			Domain: fields[0],
+			Path:   fields[1],
+			Name:   fields[2],
+			Value:  fields[3],
+			Secure: len(fields) > 5 && fields[5] == "secure",
+		}
+		if fields[4] != sessionDate {
+			expires, err := time.Parse(http.TimeFormat, fields[4])
+			if err != nil {
+				return fmt.Errorf("Cookie文件第 %d 行日期格式错误: %w", lineNum, err)
+			}
+			entry.Expires = expires
+		}
+		j.restore(entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取Cookie文件时发生错误: %w", err)
+	}
+	return nil
+}
+
+// Save 将所有未过期的Cookie保存到文件
+func (j *Jar) Save(filePath string) error {
+	var b strings.Builder
+	b.WriteString(fileHeader)
+	b.WriteString("\n")
+	for _, entry := range j.Entries() {
+		date := sessionDate
+		if !entry.Expires.IsZero() {
+			date = entry.Expires.UTC().Format(http.TimeFormat)
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%s", entry.Domain, entry.Path, entry.Name, entry.Value, date)
+		if entry.Secure {
+			b.WriteString("\tsecure")
+		}
+		b.WriteString("\n")
+	}
+
+	if dir := filepath.Dir(filePath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("无法创建Cookie文件目录: %w", err)
+		}
+	}
+	if err := os.WriteFile(filePath, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("无法写入Cookie文件: %w", err)
+	}
+	return nil
+}
+
+// restore 将从文件读取的记录放回存储
+func (j *Jar) restore(entry *Entry) {
+	if entry.expired(time.Now()) {
+		return
+	}
+
+	host := strings.TrimPrefix(entry.Domain, ".")
+	scheme := "http"
+	if entry.Secure {
+		scheme = "https"
+	}
+	cookie := &http.Cookie{
+		Name:    entry.Name,
+		Value:   entry.Value,
+		Path:    entry.Path,
+		Expires: entry.Expires,
+		Secure:  entry.Secure,
+	}
+	if strings.HasPrefix(entry.Domain, ".") {
+		cookie.Domain = host
+	}
+
+	j.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: entry.Path}, []*http.Cookie{cookie})
+}
+
+// key 返回记录的唯一标识
+func (e *Entry) key() string {
+	return e.Domain + "\t" + e.Path + "\t" + e.Name
+}
+
+// expired 判断记录是否已过期
+func (e *Entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !e.Expires.After(now)
+}
+
+// cookieDomain 按标准库cookiejar的规则检查Cookie的Domain属性，返回记录使用的域名
+// 没有Domain属性时为请求的主机名，否则为以.开头的域名；返回false表示标准库会拒绝该Cookie，
+// 例如从127.0.0.1收到Domain=other.com，或从example.com收到Domain=api.example.com
+func cookieDomain(host, domain string) (string, bool) {
+	if domain == "" {
+		return host, true
+	}
+
+	// IP地址只接受与其相同的Domain，并按没有Domain属性处理
+	if net.ParseIP(host) != nil {
+		return host, domain == host
+	}
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return "", false
+	}
+	return "." + domain, true
+}
+
+// defaultPath 按RFC 6265计算Cookie的默认路径
+func defaultPath(urlPath string) string {
+	if urlPath == "" || urlPath[0] != '/' {
+		return "/"
+	}
+	dir := path.Dir(urlPath)
+	if dir == "." {
+		return "/"
+	}
+	return dir
+}
@@ -0,0 +1,237 @@
+package cookies
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJarSaveLoad(t *testing.T) {
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	jar := NewJar()
+	jar.SetCookies(mustParseURL(t, "http://example.com/api/login"), []*http.Cookie{
+		{Name: "session", Value: "abc"},
+		{Name: "remember", Value: "1", Path: "/", Expires: expires},
+		{Name: "shared", Value: "x", Domain: "example.com", Path: "/"},
+	})
+	jar.SetCookies(mustParseURL(t, "https://secure.example.org/"), []*http.Cookie{
+		{Name: "token", Value: "t", Path: "/", Secure: true},
+	})
+
+	path := filepath.Join(t.TempDir(), "sub", "http-client.cookies")
+	if err := jar.Save(path); err != nil {
+		t.Fatalf("Save() 错误 = %v", err)
+	}
+
+	loaded := NewJar()
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load() 错误 = %v", err)
+	}
+	if got, want := loaded.Entries(), jar.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() 之后的记录 = %+v, 期望 %+v", got, want)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://example.com/api/users", "remember=1; session=abc; shared=x"},
+		{"http://example.com/other", "remember=1; shared=x"},
+		{"http://www.example.com/", "shared=x"},
+		{"http://secure.example.org/", ""},
+		{"https://secure.example.org/", "token=t"},
+	}
+	for _, tt := range tests {
+		if got := cookieHeader(loaded.Cookies(mustParseURL(t, tt.url))); got != tt.want {
+			t.Errorf("Cookies(%s) = %q, 期望 %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestJarLoad(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "会话Cookie和未过期的Cookie",
+			content: fileHeader + "\nexample.com\t/\ta\t1\t-1\n\n.example.com\t/\tb\t2\t" + future + "\n",
+			want:    []string{".example.com/b=2", "example.com/a=1"},
+		},
+		{
+			name:    "跳过已过期的Cookie",
+			content: "example.com\t/\told\t1\t" + past + "\n",
+			want:    []string{},
+		},
+		{
+			name:    "secure",
+			content: "example.com\t/\ts\t1\t-1\tsecure\n",
+			want:    []string{"example.com/s=1 secure"},
+		},
+		{
+			name:    "字段不足",
+			content: "example.com\t/\ta\t1\n",
+			wantErr: "第 1 行格式错误",
+		},
+		{
+			name:    "日期格式错误",
+			content: fileHeader + "\nexample.com\t/\ta\t1\ttomorrow\n",
+			wantErr: "第 2 行日期格式错误",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "http-client.cookies")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			jar := NewJar()
+			err := jar.Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() 错误 = %v", err)
+			}
+
+			got := make([]string, 0)
+			for _, entry := range jar.Entries() {
+				s := entry.Domain + entry.Path + entry.Name + "=" + entry.Value
+				if entry.Secure {
+					s += " secure"
+				}
+				got = append(got, s)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Entries() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJarLoadMissingFile(t *testing.T) {
+	jar := NewJar()
+	if err := jar.Load(filepath.Join(t.TempDir(), "missing.cookies")); err != nil {
+		t.Errorf("Load() 错误 = %v, 文件不存在时应该忽略", err)
+	}
+	if len(jar.Entries()) != 0 {
+		t.Errorf("Entries() = %v", jar.Entries())
+	}
+}
+
+func TestJarDeleteCookie(t *testing.T) {
+	jar := NewJar()
+	u := mustParseURL(t, "http://example.com/")
+	jar.SetCookies(u, []*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}})
+	jar.SetCookies(u, []*http.Cookie{{Name: "a", MaxAge: -1}})
+
+	if got := cookieHeader(jar.Cookies(u)); got != "b=2" {
+		t.Errorf("Cookies() = %q, 期望 %q", got, "b=2")
+	}
+	if entries := jar.Entries(); len(entries) != 1 || entries[0].Name != "b" {
+		t.Errorf("Entries() = %+v", entries)
+	}
+
+	jar.Clear()
+	if len(jar.Entries()) != 0 || len(jar.Cookies(u)) != 0 {
+		t.Error("Clear() 之后仍有Cookie")
+	}
+}
+
+func TestJarRejectedCookies(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		cookie   *http.Cookie
+		want     string // 保存的记录，为空表示Cookie被拒绝
+		replayTo string
+	}{
+		{"IP地址设置其他域名", "http://127.0.0.1:8080/", &http.Cookie{Name: "a", Value: "1", Domain: "other.com"}, "", "http://other.com/"},
+		{"IP地址设置自身", "http://127.0.0.1:8080/", &http.Cookie{Name: "a", Value: "1", Domain: "127.0.0.1"}, "127.0.0.1/a=1", "http://127.0.0.1/"},
+		{"设置其他域名", "http://example.com/", &http.Cookie{Name: "a", Value: "1", Domain: "other.com"}, "", "http://other.com/"},
+		{"设置子域名", "http://example.com/", &http.Cookie{Name: "a", Value: "1", Domain: "api.example.com"}, "", "http://api.example.com/"},
+		{"后缀相同的其他域名", "http://badexample.com/", &http.Cookie{Name: "a", Value: "1", Domain: "example.com"}, "", "http://example.com/"},
+		{"子域名设置上级域名", "http://api.Example.com/", &http.Cookie{Name: "a", Value: "1", Domain: ".EXAMPLE.com"}, ".example.com/a=1", "http://www.example.com/"},
+		{"不支持的协议", "ftp://example.com/", &http.Cookie{Name: "a", Value: "1"}, "", "http://example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jar := NewJar()
+			jar.SetCookies(mustParseURL(t, tt.url), []*http.Cookie{tt.cookie})
+
+			var got string
+			for _, entry := range jar.Entries() {
+				got += entry.Domain + entry.Path + entry.Name + "=" + entry.Value
+			}
+			if got != tt.want {
+				t.Errorf("Entries() = %q, 期望 %q", got, tt.want)
+			}
+
+			// 保存后重新加载，被拒绝的Cookie不会发送给其他域名
+			path := filepath.Join(t.TempDir(), "http-client.cookies")
+			if err := jar.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			loaded := NewJar()
+			if err := loaded.Load(path); err != nil {
+				t.Fatal(err)
+			}
+			want := ""
+			if tt.want != "" {
+				want = "a=1"
+			}
+			if got := cookieHeader(loaded.Cookies(mustParseURL(t, tt.replayTo))); got != want {
+				t.Errorf("Cookies(%s) = %q, 期望 %q", tt.replayTo, got, want)
+			}
+		})
+	}
+}
+
+func TestJarRejectedDelete(t *testing.T) {
+	jar := NewJar()
+	jar.SetCookies(mustParseURL(t, "http://example.com/"), []*http.Cookie{{Name: "a", Value: "1", Domain: "example.com", Path: "/"}})
+	// 其他域名不能删除该Cookie
+	jar.SetCookies(mustParseURL(t, "http://other.com/"), []*http.Cookie{{Name: "a", Domain: "example.com", Path: "/", MaxAge: -1}})
+
+	if entries := jar.Entries(); len(entries) != 1 || entries[0].Name != "a" {
+		t.Errorf("Entries() = %+v", entries)
+	}
+}
+
+// mustParseURL 解析测试中使用的URL
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// cookieHeader 将Cookie按名称排序后拼接，Cookie的发送顺序与创建时间有关
+func cookieHeader(cookies []*http.Cookie) string {
+	parts := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "; ")
+}
@@ -1,10 +1,12 @@
 package environment
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // 环境文件可能的名称
@@ -13,6 +15,11 @@ var envFileNames = []string{
 	"http-client.env.json",
 }
 
+// dotenv文件名称
+var dotEnvFileNames = []string{
+	".env",
+}
+
 // LoadEnvFile 从环境文件中加载环境变量
 func LoadEnvFile(filePath string, envName string) (map[string]string, error) {
 	// 读取文件内容
@@ -40,13 +47,24 @@ func LoadEnvFile(filePath string, envName string) (map[string]string, error) {
 // httpFilePath: HTTP文件的路径
 // 返回值: 环境文件路径, 是否找到, 是否需要警告(只有在上级目录找到时才为true)
 func FindEnvFile(httpFilePath string) (string, bool, bool) {
+	return findFileUpwards(httpFilePath, envFileNames)
+}
+
+// FindDotEnvFile 查找.env文件，查找策略与FindEnvFile相同
+// 返回值: .env文件路径, 是否找到, 是否在上级目录找到
+func FindDotEnvFile(httpFilePath string) (string, bool, bool) {
+	return findFileUpwards(httpFilePath, dotEnvFileNames)
+}
+
+// findFileUpwards 从HTTP文件所在目录开始逐级向上查找指定名称的文件
+func findFileUpwards(httpFilePath string, names []string) (string, bool, bool) {
 	// 获取HTTP文件所在的目录
 	httpDir := filepath.Dir(httpFilePath)
 
-	// 首先检查HTTP文件同目录下的环境文件
-	for _, name := range envFileNames {
+	// 首先检查HTTP文件同目录下的文件
+	for _, name := range names {
 		envFilePath := filepath.Join(httpDir, name)
-		if fileExists(envFilePath) {
+		if FileExists(envFilePath) {
 			// 在当前目录找到，不需要警告
 			return envFilePath, true, false
 		}
@@ -63,22 +81,83 @@ func FindEnvFile(httpFilePath string) (string, bool, bool) {
 		}
 		current = parent
 
-		// 检查这两个文件名
-		for _, name := range envFileNames {
+		// 检查所有候选文件名
+		for _, name := range names {
 			envFilePath := filepath.Join(current, name)
-			if fileExists(envFilePath) {
+			if FileExists(envFilePath) {
 				// 在上级目录找到，需要警告
 				return envFilePath, true, true
 			}
 		}
 	}
 
-	// 没有找到环境文件
+	// 没有找到文件
 	return "", false, false
 }
 
-// fileExists 检查文件是否存在
-func fileExists(path string) bool {
+// LoadDotEnvFile 加载.env文件中的变量
+// 支持 KEY=VALUE、export KEY=VALUE、#注释以及单双引号包裹的值。
+// 格式错误的行会被跳过并作为警告返回，不影响其他变量；只有无法读取文件时返回错误
+func LoadDotEnvFile(filePath string) (map[string]string, []string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法读取.env文件: %w", err)
+	}
+	defer file.Close()
+
+	vars := make(map[string]string)
+	warnings := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("第 %d 行格式错误: 缺少'='，已忽略", lineNum))
+			continue
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			warnings = append(warnings, fmt.Sprintf("第 %d 行格式错误: 变量名为空，已忽略", lineNum))
+			continue
+		}
+		vars[name] = parseDotEnvValue(strings.TrimSpace(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("读取.env文件时发生错误: %w", err)
+	}
+
+	return vars, warnings, nil
+}
+
+// parseDotEnvValue 解析.env中的值，去除引号和行尾注释
+func parseDotEnvValue(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+			return replacer.Replace(value[1 : len(value)-1])
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return value[1 : len(value)-1]
+		}
+	}
+
+	// 未加引号的值中，" #"之后的内容是注释
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		value = strings.TrimSpace(value[:idx])
+	}
+	return value
+}
+
+// FileExists 检查文件是否存在
+func FileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
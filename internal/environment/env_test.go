@@ -0,0 +1,151 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeFile 写入测试文件，自动创建目录
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDotEnvFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		want         map[string]string
+		wantWarnings []string
+	}{
+		{
+			name:    "基本格式",
+			content: "A=1\n  B = 2 \nexport C=3\n\n# 注释\nEMPTY=\n",
+			want:    map[string]string{"A": "1", "B": "2", "C": "3", "EMPTY": ""},
+		},
+		{
+			name:    "引号",
+			content: "D=\"第一行\\n第二行 \\\"引号\\\" \\\\\"\nS='不转义\\n # 不是注释'\nURL=http://a.com/?x=1=2\n",
+			want:    map[string]string{"D": "第一行\n第二行 \"引号\" \\", "S": "不转义\\n # 不是注释", "URL": "http://a.com/?x=1=2"},
+		},
+		{
+			name:    "行尾注释",
+			content: "TOKEN=abc # 测试用\nHASH=a#b\n",
+			want:    map[string]string{"TOKEN": "abc", "HASH": "a#b"},
+		},
+		{
+			name:         "格式错误的行",
+			content:      "A=1\nNOT_A_VARIABLE\n=value\nB=2\n",
+			want:         map[string]string{"A": "1", "B": "2"},
+			wantWarnings: []string{"第 2 行格式错误: 缺少'='", "第 3 行格式错误: 变量名为空"},
+		},
+		{
+			name:    "后出现的同名变量生效",
+			content: "A=1\nA=2\n",
+			want:    map[string]string{"A": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			writeFile(t, path, tt.content)
+
+			vars, warnings, err := LoadDotEnvFile(path)
+			if err != nil {
+				t.Fatalf("LoadDotEnvFile() 错误 = %v", err)
+			}
+			if !reflect.DeepEqual(vars, tt.want) {
+				t.Errorf("变量 = %q, 期望 %q", vars, tt.want)
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("警告 = %v, 期望 %v", warnings, tt.wantWarnings)
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.Contains(warnings[i], want) {
+					t.Errorf("警告[%d] = %q, 期望包含 %q", i, warnings[i], want)
+				}
+			}
+		})
+	}
+
+	if _, _, err := LoadDotEnvFile(filepath.Join(t.TempDir(), ".env")); err == nil {
+		t.Error("文件不存在时应该返回错误")
+	}
+}
+
+func TestFindDotEnvFile(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".env"), "A=1\n")
+	writeFile(t, filepath.Join(root, "same", ".env"), "A=2\n")
+
+	tests := []struct {
+		name       string
+		httpFile   string
+		wantPath   string
+		wantParent bool
+	}{
+		{"同一目录", filepath.Join(root, "same", "api.http"), filepath.Join(root, "same", ".env"), false},
+		{"上级目录", filepath.Join(root, "sub", "deeper", "api.http"), filepath.Join(root, ".env"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, found, parent := FindDotEnvFile(tt.httpFile)
+			if !found || path != tt.wantPath || parent != tt.wantParent {
+				t.Errorf("FindDotEnvFile() = %q, %v, %v, 期望 %q, true, %v", path, found, parent, tt.wantPath, tt.wantParent)
+			}
+		})
+	}
+}
+
+func TestFindEnvFile(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "http-client.env.json"), "{}")
+	writeFile(t, filepath.Join(root, "api", "http-client.env.json"), "{}")
+	writeFile(t, filepath.Join(root, "api", "http-client.private.env.json"), "{}")
+
+	path, found, parent := FindEnvFile(filepath.Join(root, "api", "test.http"))
+	if !found || parent || path != filepath.Join(root, "api", "http-client.private.env.json") {
+		t.Errorf("FindEnvFile() = %q, %v, %v, 私有环境文件优先", path, found, parent)
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "http-client.env.json")
+	writeFile(t, path, `{"dev": {"host": "localhost"}, "prod": {"host": "example.com"}}`)
+
+	env, err := LoadEnvFile(path, "prod")
+	if err != nil || env["host"] != "example.com" {
+		t.Errorf("LoadEnvFile() = %v, %v", env, err)
+	}
+	if _, err := LoadEnvFile(path, "test"); err == nil || !strings.Contains(err.Error(), "环境 'test' 不存在") {
+		t.Errorf("LoadEnvFile() 错误 = %v", err)
+	}
+
+	names, err := ListEnvironments(path)
+	sort.Strings(names)
+	if err != nil || !reflect.DeepEqual(names, []string{"dev", "prod"}) {
+		t.Errorf("ListEnvironments() = %v, %v", names, err)
+	}
+}
+
+func TestFileExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if FileExists(path) {
+		t.Error("FileExists() = true, 文件不存在")
+	}
+	writeFile(t, path, "")
+	if !FileExists(path) {
+		t.Error("FileExists() = false, 文件存在")
+	}
+}
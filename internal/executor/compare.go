@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shellus/jhttp/internal/compare"
+	"github.com/shellus/jhttp/internal/environment"
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// maxReportedDiffs 比较结果中最多列出的差异数量
+const maxReportedDiffs = 20
+
+// compareWithReference 将响应与第一个存在的参考响应文件进行比较
+func compareWithReference(refs []string, resp *models.HTTPResponse) models.TestResult {
+	result := models.TestResult{Name: "与参考响应比较"}
+
+	path, found := findReferenceFile(refs)
+	if !found {
+		result.Message = fmt.Sprintf("参考响应文件不存在: %s", strings.Join(refs, ", "))
+		return result
+	}
+	result.Name = fmt.Sprintf("与参考响应比较 (%s)", filepath.Base(path))
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		result.Message = fmt.Sprintf("无法读取参考响应文件: %v", err)
+		return result
+	}
+
+	diffs := compare.Diff(expected, resp.Body)
+	if len(diffs) == 0 {
+		result.Passed = true
+		return result
+	}
+
+	if len(diffs) > maxReportedDiffs {
+		omitted := len(diffs) - maxReportedDiffs
+		diffs = append(diffs[:maxReportedDiffs], fmt.Sprintf("... 另有 %d 处差异", omitted))
+	}
+	result.Message = fmt.Sprintf("发现差异:\n      %s", strings.Join(diffs, "\n      "))
+	return result
+}
+
+// findReferenceFile 查找参考响应文件
+// IntelliJ将响应保存在.idea/httpRequests目录中，相对.http文件找不到时会逐级向上在该目录中查找
+func findReferenceFile(refs []string) (string, bool) {
+	for _, ref := range refs {
+		if environment.FileExists(ref) {
+			return ref, true
+		}
+
+		name := filepath.Base(ref)
+		dir := filepath.Dir(ref)
+		for {
+			candidate := filepath.Join(dir, ".idea", "httpRequests", name)
+			if environment.FileExists(candidate) {
+				return candidate, true
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	return "", false
+}
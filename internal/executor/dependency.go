@@ -0,0 +1,181 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shellus/jhttp/internal/models"
+	"github.com/shellus/jhttp/internal/parser"
+	"github.com/shellus/jhttp/internal/script"
+)
+
+// 脚本中读写全局变量的调用：client.global.set("name", ...) 和 client.global.get("name")
+// 依赖分析只扫描脚本源码，变量名必须是字符串字面量（双引号、单引号或不含${}的模板字符串）。
+// 计算出的变量名（如client.global.set(name, ...)、"token_" + id、`token_${id}`）无法识别，
+// 这样的请求之间不会自动排序
+var (
+	globalSetRegex = regexp.MustCompile("client\\.global\\.set\\(\\s*[\"'`]([^\"'`$\\\\]+)[\"'`]\\s*,")
+	globalGetRegex = regexp.MustCompile("client\\.global\\.get\\(\\s*[\"'`]([^\"'`$\\\\]+)[\"'`]\\s*\\)")
+)
+
+// dependencyGraph 请求之间的依赖关系
+// 请求A消费的变量由请求B的脚本通过client.global.set产生，或A引用了B的响应时，A依赖B
+type dependencyGraph struct {
+	requests []*models.HTTPRequest
+	deps     map[*models.HTTPRequest][]*models.HTTPRequest
+}
+
+// buildDependencyGraph 根据变量的消费和产生关系构建依赖图，env为使用的环境
+func buildDependencyGraph(httpFile *models.HTTPFile, env string) *dependencyGraph {
+	graph := &dependencyGraph{
+		requests: httpFile.Requests,
+		deps:     make(map[*models.HTTPRequest][]*models.HTTPRequest),
+	}
+
+	// 收集每个变量的产生者，按文件顺序排列
+	producers := make(map[string][]int)
+	for i, req := range httpFile.Requests {
+		for _, name := range scriptGlobals(req, globalSetRegex) {
+			producers[name] = append(producers[name], i)
+		}
+	}
+
+	for i, req := range httpFile.Requests {
+		seen := make(map[*models.HTTPRequest]bool)
+		addDep := func(dep *models.HTTPRequest) {
+			if dep != nil && dep != req && !seen[dep] {
+				seen[dep] = true
+				graph.deps[req] = append(graph.deps[req], dep)
+			}
+		}
+
+		// 对其他命名请求的引用
+		for _, name := range parser.RequestReferences(httpFile, req) {
+			addDep(httpFile.FindRequestByName(name))
+		}
+
+		// 消费的变量：{{变量}}引用以及预请求脚本中的client.global.get
+		consumed := parser.VariableReferences(httpFile, req)
+		if req.PreRequestScript != nil {
+			consumed = append(consumed, scriptSourceGlobals(req.PreRequestScript, globalGetRegex)...)
+		}
+		for _, name := range consumed {
+			if producer := chooseProducer(producers[name], i, isDefined(httpFile, env, name)); producer >= 0 {
+				addDep(httpFile.Requests[producer])
+			}
+		}
+	}
+
+	return graph
+}
+
+// chooseProducer 为位于index的请求选择变量的产生者：
+// 优先选择文件中位于它之前的最后一个产生者，没有时选择之后的第一个产生者，不能是请求自身。
+// 变量已在环境或文件变量中定义时（defined）不选择之后的产生者，避免改变请求在文件中的顺序
+func chooseProducer(candidates []int, index int, defined bool) int {
+	before, after := -1, -1
+	for _, c := range candidates {
+		switch {
+		case c < index:
+			before = c
+		case c > index && after < 0:
+			after = c
+		}
+	}
+	if before >= 0 || defined {
+		return before
+	}
+	return after
+}
+
+// isDefined 判断变量是否在使用的环境或文件变量（@name = value）中定义
+func isDefined(httpFile *models.HTTPFile, env string, name string) bool {
+	if _, ok := httpFile.GlobalVars[name]; ok {
+		return true
+	}
+	_, ok := httpFile.EnvironmentVars[env][name]
+	return ok
+}
+
+// order 返回执行targets所需的全部请求的执行顺序（拓扑排序）
+// 前置请求排在依赖它的请求之前，没有依赖关系的请求保持文件中的顺序；存在循环依赖时返回错误
+func (g *dependencyGraph) order(targets []*models.HTTPRequest) ([]*models.HTTPRequest, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*models.HTTPRequest]int)
+	result := make([]*models.HTTPRequest, 0, len(targets))
+	path := make([]*models.HTTPRequest, 0)
+
+	var visit func(req *models.HTTPRequest) error
+	visit = func(req *models.HTTPRequest) error {
+		switch state[req] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("请求之间存在循环依赖: %s", describeCycle(path, req))
+		}
+
+		state[req] = visiting
+		path = append(path, req)
+		for _, dep := range g.deps[req] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[req] = done
+		result = append(result, req)
+		return nil
+	}
+
+	for _, req := range targets {
+		if err := visit(req); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// describeCycle 描述循环依赖的路径，如 A -> B -> A
+func describeCycle(path []*models.HTTPRequest, repeated *models.HTTPRequest) string {
+	names := make([]string, 0, len(path)+1)
+	start := 0
+	for i, req := range path {
+		if req == repeated {
+			start = i
+			break
+		}
+	}
+	for _, req := range path[start:] {
+		names = append(names, req.DisplayName())
+	}
+	names = append(names, repeated.DisplayName())
+	return strings.Join(names, " -> ")
+}
+
+// scriptGlobals 返回请求的预请求脚本和响应处理脚本中匹配的全局变量名称
+func scriptGlobals(req *models.HTTPRequest, pattern *regexp.Regexp) []string {
+	names := make([]string, 0)
+	for _, s := range []*models.Script{req.PreRequestScript, req.ResponseHandler} {
+		if s != nil {
+			names = append(names, scriptSourceGlobals(s, pattern)...)
+		}
+	}
+	return names
+}
+
+// scriptSourceGlobals 返回脚本源码中匹配的全局变量名称，无法读取的脚本文件会被忽略
+func scriptSourceGlobals(s *models.Script, pattern *regexp.Regexp) []string {
+	source, err := script.LoadSource(s)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0)
+	for _, match := range pattern.FindAllStringSubmatch(source, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
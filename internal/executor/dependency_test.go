@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// newDependencyRequest 创建测试用的命名请求，header为Authorization头，handler为响应处理脚本
+func newDependencyRequest(name, header, handler string) *models.HTTPRequest {
+	req := &models.HTTPRequest{Name: name, Method: "GET", Headers: make(http.Header)}
+	req.URL, _ = url.Parse("http://example.com/" + name)
+	if header != "" {
+		req.Headers.Set("Authorization", header)
+	}
+	if handler != "" {
+		req.ResponseHandler = &models.Script{Content: handler}
+	}
+	return req
+}
+
+// requestNames 返回请求名称列表
+func requestNames(requests []*models.HTTPRequest) []string {
+	names := make([]string, 0, len(requests))
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	return names
+}
+
+func TestChooseProducer(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []int
+		index      int
+		defined    bool
+		want       int
+	}{
+		{"没有产生者", nil, 2, false, -1},
+		{"之前的最后一个产生者", []int{0, 1, 3}, 2, false, 1},
+		{"之前没有时使用之后的第一个", []int{3, 5}, 2, false, 3},
+		{"不能是请求自身", []int{2}, 2, false, -1},
+		{"跳过自身选择之后的产生者", []int{2, 4}, 2, false, 4},
+		{"已定义时不使用之后的产生者", []int{3, 5}, 2, true, -1},
+		{"已定义时仍使用之前的产生者", []int{0, 3}, 2, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chooseProducer(tt.candidates, tt.index, tt.defined); got != tt.want {
+				t.Errorf("chooseProducer() = %d, 期望 %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScriptGlobals(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		set    []string
+		get    []string
+	}{
+		{"双引号", `client.global.set("token", response.body.token); client.global.get("id")`, []string{"token"}, []string{"id"}},
+		{"单引号", `client.global.set('token', 1); client.global.get( 'id' )`, []string{"token"}, []string{"id"}},
+		{"模板字符串", "client.global.set(`token`, 1); client.global.get(`id`)", []string{"token"}, []string{"id"}},
+		{"多个调用", `client.global.set("a", 1);
+client.global.set( "b" , 2);`, []string{"a", "b"}, []string{}},
+		// 计算出的变量名无法识别
+		{"变量", `var name = "token"; client.global.set(name, 1); client.global.get(name)`, []string{}, []string{}},
+		{"字符串拼接", `client.global.set("token_" + id, 1); client.global.get("token_" + id)`, []string{}, []string{}},
+		{"带插值的模板字符串", "client.global.set(`token_${id}`, 1); client.global.get(`token_${id}`)", []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &models.Script{Content: tt.source}
+			if got := scriptSourceGlobals(s, globalSetRegex); !reflect.DeepEqual(got, tt.set) {
+				t.Errorf("set = %v, 期望 %v", got, tt.set)
+			}
+			if got := scriptSourceGlobals(s, globalGetRegex); !reflect.DeepEqual(got, tt.get) {
+				t.Errorf("get = %v, 期望 %v", got, tt.get)
+			}
+		})
+	}
+}
+
+func TestDependencyOrder(t *testing.T) {
+	// 文件顺序：获取用户、登录、列表、详情
+	// 获取用户使用登录设置的token，详情引用了列表的响应
+	newFile := func() *models.HTTPFile {
+		httpFile := models.NewHTTPFile("test.http")
+		httpFile.AddRequest(newDependencyRequest("获取用户", "Bearer {{token}}", ""))
+		httpFile.AddRequest(newDependencyRequest("登录", "", `client.global.set("token", response.body.token);`))
+		httpFile.AddRequest(newDependencyRequest("列表", "", ""))
+		httpFile.AddRequest(newDependencyRequest("详情", "{{列表.response.body.$.id}}", ""))
+		return httpFile
+	}
+
+	tests := []struct {
+		name    string
+		targets []string // 为空时执行全部请求
+		env     map[string]string
+		want    []string
+	}{
+		{name: "全部请求", want: []string{"登录", "获取用户", "列表", "详情"}},
+		{name: "只执行获取用户", targets: []string{"获取用户"}, want: []string{"登录", "获取用户"}},
+		{name: "只执行详情", targets: []string{"详情"}, want: []string{"列表", "详情"}},
+		{name: "多个目标共享前置请求", targets: []string{"详情", "获取用户", "登录"}, want: []string{"列表", "详情", "登录", "获取用户"}},
+		{name: "环境中已定义变量", env: map[string]string{"token": "abc"}, want: []string{"获取用户", "登录", "列表", "详情"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpFile := newFile()
+			httpFile.EnvironmentVars["dev"] = tt.env
+			targets := httpFile.Requests
+			if len(tt.targets) > 0 {
+				targets = nil
+				for _, name := range tt.targets {
+					targets = append(targets, httpFile.FindRequestByName(name))
+				}
+			}
+
+			ordered, err := buildDependencyGraph(httpFile, "dev").order(targets)
+			if err != nil {
+				t.Fatalf("order() 错误 = %v", err)
+			}
+			if got := requestNames(ordered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	httpFile := models.NewHTTPFile("test.http")
+	httpFile.AddRequest(newDependencyRequest("无关", "", ""))
+	httpFile.AddRequest(newDependencyRequest("A", "{{b}}", `client.global.set("a", 1);`))
+	httpFile.AddRequest(newDependencyRequest("B", "{{c}}", `client.global.set("b", 1);`))
+	httpFile.AddRequest(newDependencyRequest("C", "{{A.response.body.*}}", `client.global.set("c", 1);`))
+
+	_, err := buildDependencyGraph(httpFile, "").order(httpFile.Requests)
+	if err == nil || !strings.Contains(err.Error(), "循环依赖: A -> B -> C -> A") {
+		t.Errorf("order() 错误 = %v, 期望报告循环依赖", err)
+	}
+
+	// 不涉及循环的请求可以单独执行
+	ordered, err := buildDependencyGraph(httpFile, "").order(httpFile.Requests[:1])
+	if err != nil || !reflect.DeepEqual(requestNames(ordered), []string{"无关"}) {
+		t.Errorf("order() = %v, %v", requestNames(ordered), err)
+	}
+}
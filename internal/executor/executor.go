@@ -2,26 +2,55 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/shellus/jhttp/internal/assertion"
+	"github.com/shellus/jhttp/internal/cookies"
+	"github.com/shellus/jhttp/internal/format"
 	"github.com/shellus/jhttp/internal/models"
 	"github.com/shellus/jhttp/internal/parser"
+	"github.com/shellus/jhttp/internal/script"
 )
 
 // Executor HTTP请求执行器
 type Executor struct {
-	client  *http.Client
-	verbose bool
+	client     *http.Client
+	cookieJar  *cookies.Jar // 所有请求共享的Cookie存储
+	verbose    bool
+	compare    bool                              // 是否与参考响应（<> 文件）比较
+	transports map[time.Duration]*http.Transport // 按连接超时时间缓存的Transport
+	delay      time.Duration                     // 顺序执行时请求之间的间隔
+	output     format.Options                    // 详细模式下请求体和响应体的输出格式
+	onResponse func(*models.HTTPFile, *models.HTTPResponse)
+
+	failFast     bool           // 遇到第一个失败的请求时停止执行
+	failOnStatus StatusPatterns // 视为失败的响应状态码
+
+	mu       sync.Mutex                   // 并行执行时保护以下状态
+	executed map[*models.HTTPRequest]bool // 已执行的请求（包括按需执行的依赖请求）
+
+	saveMu     sync.Mutex     // 保存响应时选择文件名的锁
+	saveSuffix map[string]int // 每个保存路径下一个可能可用的序号，避免每次保存都从1开始检查
 }
 
 // NewExecutor 创建一个新的执行器
 func NewExecutor(verbose bool) *Executor {
+	jar := cookies.NewJar()
 	return &Executor{
 		client: &http.Client{
+			Jar:     jar,
 			Timeout: 30 * time.Second,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				// 允许最多5次重定向
@@ -31,27 +60,144 @@ func NewExecutor(verbose bool) *Executor {
 				return nil
 			},
 		},
-		verbose: verbose,
+		cookieJar: jar,
+		verbose:   verbose,
+		delay:     200 * time.Millisecond,
+		executed:  make(map[*models.HTTPRequest]bool),
+
+		saveSuffix: make(map[string]int),
 	}
 }
 
+// CookieJar 返回执行器使用的Cookie存储，可用于加载或保存Cookie
+func (e *Executor) CookieJar() *cookies.Jar {
+	return e.cookieJar
+}
+
 // SetTimeout 设置HTTP请求超时时间
 func (e *Executor) SetTimeout(timeout time.Duration) {
 	e.client.Timeout = timeout
 }
 
+// SetCompare 设置是否将响应与请求中引用的参考响应（<> 文件）进行比较
+func (e *Executor) SetCompare(enabled bool) {
+	e.compare = enabled
+}
+
+// SetOutputOptions 设置详细模式下请求体和响应体的输出格式
+func (e *Executor) SetOutputOptions(opts format.Options) {
+	e.output = opts
+}
+
+// SetResponseCallback 设置每个请求执行完成后的回调，用于流式输出结果
+// 并行执行时回调会在多个协程中同时调用
+func (e *Executor) SetResponseCallback(callback func(httpFile *models.HTTPFile, resp *models.HTTPResponse)) {
+	e.onResponse = callback
+}
+
+// SetFailOnStatus 设置视为失败的响应状态码，影响--fail-fast的判断
+func (e *Executor) SetFailOnStatus(patterns StatusPatterns) {
+	e.failOnStatus = patterns
+}
+
+// SetFailFast 设置是否在第一个失败的请求（网络错误、匹配的状态码、测试失败）之后停止执行后续请求
+func (e *Executor) SetFailFast(enabled bool) {
+	e.failFast = enabled
+}
+
+// shouldStop 判断启用--fail-fast时是否因为这些响应停止执行
+func (e *Executor) shouldStop(responses ...*models.HTTPResponse) bool {
+	return e.failFast && WorstFailure(responses, e.failOnStatus) != FailureNone
+}
+
+// SetDelay 设置顺序执行时请求之间的间隔，为0时不等待
+func (e *Executor) SetDelay(delay time.Duration) {
+	e.delay = delay
+}
+
 // Execute 执行单个HTTP请求
+// 请求中引用了尚未执行的命名请求（如{{login.response.body.$.token}}）时，会先执行被引用的请求
 func (e *Executor) Execute(httpFile *models.HTTPFile, request *models.HTTPRequest, env string) (*models.HTTPResponse, error) {
-	// 解析请求中的变量
-	resolvedReq, err := parser.ResolveVariables(httpFile, request, env)
+	resp, _, err := e.executeWithDependencies(httpFile, request, env)
+	return resp, err
+}
+
+// executeWithDependencies 执行单个HTTP请求，同时返回按需执行的依赖请求的响应（按执行顺序）
+// 依赖请求的响应随调用返回，并行执行时不会混入其他请求的结果
+func (e *Executor) executeWithDependencies(httpFile *models.HTTPFile, request *models.HTTPRequest, env string) (*models.HTTPResponse, []*models.HTTPResponse, error) {
+	return e.executeInChain(httpFile, request, env, nil)
+}
+
+// executeInChain 执行请求，chain为等待该请求执行完成的命名请求（引用链），用于检测循环引用
+// 引用链随调用传递，并行执行的请求之间互不影响
+func (e *Executor) executeInChain(httpFile *models.HTTPFile, request *models.HTTPRequest, env string, chain []string) (*models.HTTPResponse, []*models.HTTPResponse, error) {
+	if request.Name != "" {
+		chain = append(chain[:len(chain):len(chain)], request.Name)
+	}
+
+	dependencies, err := e.executeReferences(httpFile, request, env, chain)
 	if err != nil {
-		return nil, fmt.Errorf("解析变量失败: %w", err)
+		return nil, dependencies, err
 	}
 
-	// 创建HTTP请求
-	req, err := e.createHTTPRequest(resolvedReq)
+	resp, err := e.execute(httpFile, request, env)
 	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+		return nil, dependencies, err
+	}
+
+	e.markExecuted(request)
+	if request.Name != "" {
+		httpFile.SetNamedResponse(request.Name, resp)
+	}
+	if e.onResponse != nil {
+		e.onResponse(httpFile, resp)
+	}
+	return resp, dependencies, nil
+}
+
+// executeReferences 按需执行请求中引用的、尚未执行的命名请求，返回这些请求（包括它们的依赖）的响应
+func (e *Executor) executeReferences(httpFile *models.HTTPFile, request *models.HTTPRequest, env string, chain []string) ([]*models.HTTPResponse, error) {
+	responses := make([]*models.HTTPResponse, 0)
+	for _, name := range parser.RequestReferences(httpFile, request) {
+		if _, done := httpFile.NamedResponse(name); done {
+			continue
+		}
+		if slices.Contains(chain, name) {
+			return responses, fmt.Errorf("请求 '%s' 与 '%s' 之间存在循环引用", request.DisplayName(), name)
+		}
+
+		if e.verbose {
+			fmt.Printf("\n===== 执行依赖请求: %s =====\n", name)
+		}
+		resp, dependencies, err := e.executeInChain(httpFile, httpFile.FindRequestByName(name), env, chain)
+		responses = append(responses, dependencies...)
+		if err != nil {
+			return responses, fmt.Errorf("执行依赖请求 '%s' 失败: %w", name, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// markExecuted 标记请求已执行
+func (e *Executor) markExecuted(req *models.HTTPRequest) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.executed[req] = true
+}
+
+// isExecuted 判断请求是否已执行
+func (e *Executor) isExecuted(req *models.HTTPRequest) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.executed[req]
+}
+
+// execute 执行单个HTTP请求，不处理请求之间的引用
+func (e *Executor) execute(httpFile *models.HTTPFile, request *models.HTTPRequest, env string) (*models.HTTPResponse, error) {
+	resolvedReq, req, failed, err := e.prepare(httpFile, request, env)
+	if err != nil || failed != nil {
+		return failed, err
 	}
 
 	// 如果启用详细模式，打印请求信息
@@ -62,68 +208,45 @@ func (e *Executor) Execute(httpFile *models.HTTPFile, request *models.HTTPReques
 				fmt.Printf("> %s: %s\n", key, value)
 			}
 		}
-		if req.Body != nil && resolvedReq.Body != "" {
+		if resolvedReq.BodyFile != "" {
+			fmt.Println(">")
+			fmt.Printf("< %s\n", resolvedReq.BodyFile)
+		} else if req.Body != nil && resolvedReq.Body != "" {
 			fmt.Println(">")
-			fmt.Println(resolvedReq.Body)
+			fmt.Println(format.Body(req.Header.Get("Content-Type"), resolvedReq.Body, e.output))
 		}
 		fmt.Println()
 	}
 
 	// 添加一个有超时的上下文
-	ctx, cancel := context.WithTimeout(context.Background(), e.client.Timeout)
+	client := e.clientFor(resolvedReq)
+	ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
 	defer cancel()
-	req = req.WithContext(ctx)
 
-	// 执行请求
+	// 执行请求，通过httptrace记录各阶段耗时
 	startTime := time.Now()
-	resp, err := e.client.Do(req)
+	tracer := newTimingTracer(startTime)
+	req = req.WithContext(httptrace.WithClientTrace(ctx, tracer.trace()))
+	resp, err := client.Do(req)
 	duration := time.Since(startTime)
 
 	// 处理请求错误
 	if err != nil {
-		errorMessage := err.Error()
-		errorType := "网络错误"
-
-		// 检查不同类型的错误
-		switch {
-		case strings.Contains(errorMessage, "context deadline exceeded") ||
-			strings.Contains(errorMessage, "timeout") ||
-			strings.Contains(errorMessage, "timed out"):
-			errorType = "请求超时"
-			errorMessage = "请求超时 - 服务器在规定时间内没有响应"
-
-		case strings.Contains(errorMessage, "no such host"):
-			errorType = "域名解析错误"
-			errorMessage = fmt.Sprintf("无法解析主机名 '%s'", req.URL.Host)
-
-		case strings.Contains(errorMessage, "connection refused"):
-			errorType = "连接被拒绝"
-			errorMessage = fmt.Sprintf("连接被拒绝 - 服务器 '%s' 拒绝了连接请求", req.URL.Host)
-
-		case strings.Contains(errorMessage, "certificate"):
-			errorType = "SSL/TLS 错误"
-			errorMessage = "SSL/TLS 证书验证失败"
-
-		case strings.Contains(errorMessage, "no route to host"):
-			errorType = "路由错误"
-			errorMessage = fmt.Sprintf("无法连接到主机 '%s' - 网络不可达", req.URL.Host)
-
-		case strings.Contains(errorMessage, "i/o timeout"):
-			errorType = "I/O 超时"
-			errorMessage = "读取/写入操作超时 - 可能是网络问题或服务器响应缓慢"
-		}
+		errorType, errorMessage := describeError(err, req)
 
 		// 打印详细的错误信息
 		if e.verbose {
 			fmt.Printf("\n请求失败: [%s] %s\n", errorType, errorMessage)
 			fmt.Printf("原始错误: %v\n", err)
 			fmt.Printf("请求耗时: %d ms\n", duration.Milliseconds())
+			printTiming(tracer.finish(time.Now()))
 		}
 
 		return &models.HTTPResponse{
 			Request: resolvedReq,
 			Error:   fmt.Errorf("%s: %s", errorType, errorMessage),
 			Time:    duration.Milliseconds(),
+			Timing:  tracer.finish(time.Now()),
 		}, nil
 	}
 	defer resp.Body.Close()
@@ -143,6 +266,7 @@ func (e *Executor) Execute(httpFile *models.HTTPFile, request *models.HTTPReques
 		BodyString: string(body),
 		Time:       duration.Milliseconds(),
 		Request:    resolvedReq,
+		Timing:     tracer.finish(time.Now()),
 	}
 
 	// 如果启用详细模式，打印响应信息
@@ -156,64 +280,255 @@ func (e *Executor) Execute(httpFile *models.HTTPFile, request *models.HTTPReques
 
 		if len(response.Body) > 0 {
 			fmt.Println("<")
+			fmt.Println(format.Body(resp.Header.Get("Content-Type"), response.BodyString, e.output))
+		}
 
-			// 检查Content-Type以决定如何格式化输出
-			contentType := resp.Header.Get("Content-Type")
-			if strings.Contains(contentType, "application/json") {
-				// 尝试美化JSON输出（这是简化版，实际中可以使用json包格式化）
-				fmt.Println(response.BodyString)
-			} else {
-				fmt.Println(response.BodyString)
+		fmt.Printf("\n请求耗时: %d ms\n", response.Time)
+		printTiming(response.Timing)
+	}
+
+	// 按>>或>>!指令保存响应
+	if resolvedReq.ResponseOutput != "" {
+		path, err := e.saveResponse(resolvedReq.ResponseOutput, resolvedReq.ResponseOutputOverwrite, response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("保存响应失败: %w", err)
+		}
+		response.OutputFile = path
+		if e.verbose {
+			fmt.Printf("响应已保存到文件: %s\n", path)
+		}
+	}
+
+	// 执行响应处理脚本
+	if request.ResponseHandler != nil {
+		if err := script.RunResponseHandler(httpFile, request.ResponseHandler, response); err != nil {
+			response.ScriptError = fmt.Errorf("响应处理脚本: %w", err)
+			if e.verbose {
+				fmt.Printf("脚本错误: %v\n", response.ScriptError)
 			}
 		}
 
-		fmt.Printf("\n请求耗时: %d ms\n", response.Time)
+	}
+
+	// 执行声明式断言
+	for _, a := range resolvedReq.Assertions {
+		response.Tests = append(response.Tests, assertion.Evaluate(a, response))
+	}
+
+	// 与参考响应比较
+	if e.compare && len(resolvedReq.ResponseRefs) > 0 {
+		response.Tests = append(response.Tests, compareWithReference(resolvedReq.ResponseRefs, response))
+	}
+
+	if e.verbose {
+		printTestResults(response)
 	}
 
 	return response, nil
 }
 
+// prepare 执行预请求脚本并替换变量，创建要发送的HTTP请求
+// 预请求脚本出错时返回表示该错误的响应（failed），其他错误通过err返回
+func (e *Executor) prepare(httpFile *models.HTTPFile, request *models.HTTPRequest, env string) (resolvedReq *models.HTTPRequest, req *http.Request, failed *models.HTTPResponse, err error) {
+	// 执行预请求脚本，脚本设置的请求变量需要在变量替换之前生效
+	if request.PreRequestScript != nil {
+		prepared := *request
+		prepared.Variables = make(map[string]string, len(request.Variables))
+		for name, value := range request.Variables {
+			prepared.Variables[name] = value
+		}
+
+		if err := script.RunPreRequest(httpFile, request.PreRequestScript, &prepared, env); err != nil {
+			if e.verbose {
+				fmt.Printf("预请求脚本错误: %v\n", err)
+			}
+			return nil, nil, &models.HTTPResponse{
+				Request: request,
+				Error:   fmt.Errorf("预请求脚本: %w", err),
+			}, nil
+		}
+		request = &prepared
+	}
+
+	// 解析请求中的变量
+	resolvedReq, err = parser.ResolveVariables(httpFile, request, env)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("解析变量失败: %w", err)
+	}
+
+	// 创建HTTP请求
+	req, err = e.createHTTPRequest(resolvedReq)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	return resolvedReq, req, nil, nil
+}
+
+// describeError 返回请求错误的类型和便于理解的描述
+func describeError(err error, req *http.Request) (string, string) {
+	errorMessage := err.Error()
+	errorType := "网络错误"
+
+	// 检查不同类型的错误
+	switch {
+	case strings.Contains(errorMessage, "context deadline exceeded") ||
+		strings.Contains(errorMessage, "timeout") ||
+		strings.Contains(errorMessage, "timed out"):
+		errorType = "请求超时"
+		errorMessage = "请求超时 - 服务器在规定时间内没有响应"
+
+	case strings.Contains(errorMessage, "no such host"):
+		errorType = "域名解析错误"
+		errorMessage = fmt.Sprintf("无法解析主机名 '%s'", req.URL.Host)
+
+	case strings.Contains(errorMessage, "connection refused"):
+		errorType = "连接被拒绝"
+		errorMessage = fmt.Sprintf("连接被拒绝 - 服务器 '%s' 拒绝了连接请求", req.URL.Host)
+
+	case strings.Contains(errorMessage, "certificate"):
+		errorType = "SSL/TLS 错误"
+		errorMessage = "SSL/TLS 证书验证失败"
+
+	case strings.Contains(errorMessage, "no route to host"):
+		errorType = "路由错误"
+		errorMessage = fmt.Sprintf("无法连接到主机 '%s' - 网络不可达", req.URL.Host)
+
+	case strings.Contains(errorMessage, "i/o timeout"):
+		errorType = "I/O 超时"
+		errorMessage = "读取/写入操作超时 - 可能是网络问题或服务器响应缓慢"
+	}
+	return errorType, errorMessage
+}
+
 // ExecuteFile 执行HTTP文件中的所有请求
-func (e *Executor) ExecuteFile(httpFile *models.HTTPFile, requestName string, env string) ([]*models.HTTPResponse, error) {
+// 请求之间的依赖（脚本设置的全局变量、对命名请求的引用）会自动按拓扑顺序执行，
+// 只执行选中的请求时也会先执行它们依赖的前置请求。启用--fail-fast时返回已执行的请求的响应
+// 请求执行出错时停止执行，返回错误和已执行的请求的响应，出错的请求记录为一个带有错误的响应
+func (e *Executor) ExecuteFile(httpFile *models.HTTPFile, selector *Selector, env string) ([]*models.HTTPResponse, error) {
 	responses := make([]*models.HTTPResponse, 0)
 
-	// 如果指定了请求名称，只执行该请求
-	if requestName != "" {
-		req := httpFile.FindRequestByName(requestName)
-		if req == nil {
-			return nil, fmt.Errorf("未找到名为 '%s' 的请求", requestName)
-		}
+	targets, err := targetRequests(httpFile, selector)
+	if err != nil {
+		return nil, err
+	}
 
-		resp, err := e.Execute(httpFile, req, env)
-		if err != nil {
-			return nil, err
-		}
-		responses = append(responses, resp)
-		return responses, nil
+	// 按依赖关系排序
+	ordered, err := buildDependencyGraph(httpFile, env).order(targets)
+	if err != nil {
+		return nil, err
+	}
+	if err := selector.checkPrerequisites(ordered); err != nil {
+		return nil, err
+	}
+
+	isTarget := make(map[*models.HTTPRequest]bool, len(targets))
+	for _, req := range targets {
+		isTarget[req] = true
 	}
 
-	// 否则，执行所有请求
-	for _, req := range httpFile.Requests {
+	for i, req := range ordered {
+		// 已经作为其他请求的依赖执行过的请求不再重复执行
+		if e.isExecuted(req) {
+			continue
+		}
+
 		if e.verbose {
-			fmt.Printf("\n===== 执行请求: %s =====\n", req.Name)
+			if isTarget[req] {
+				fmt.Printf("\n===== 执行请求: %s =====\n", req.Name)
+			} else {
+				fmt.Printf("\n===== 执行前置请求: %s =====\n", req.Name)
+			}
 			if req.Description != "" {
 				fmt.Printf("描述: %s\n\n", req.Description)
 			}
 		}
 
-		resp, err := e.Execute(httpFile, req, env)
+		resp, dependencies, err := e.executeWithDependencies(httpFile, req, env)
+		responses = append(responses, dependencies...)
 		if err != nil {
-			return nil, fmt.Errorf("执行请求 '%s' 失败: %w", req.Name, err)
+			err = fmt.Errorf("执行请求 '%s' 失败: %w", req.DisplayName(), err)
+			return append(responses, e.failedResponse(httpFile, req, err)), err
 		}
 		responses = append(responses, resp)
 
-		// 请求之间添加一些延迟，避免过快请求服务器
-		time.Sleep(200 * time.Millisecond)
+		// 启用--fail-fast时，出现失败后不再执行后续请求
+		if e.shouldStop(append(dependencies, resp)...) {
+			if e.verbose {
+				fmt.Printf("\n请求 '%s' 失败，停止执行后续请求\n", req.DisplayName())
+			}
+			break
+		}
+
+		// 请求之间添加间隔，避免过快请求服务器
+		if e.delay > 0 && i < len(ordered)-1 {
+			time.Sleep(e.delay)
+		}
 	}
 
 	return responses, nil
 }
 
+// failedResponse 将执行出错的请求记录为一个带有错误的响应，使JSON输出和报告中包含这个请求
+func (e *Executor) failedResponse(httpFile *models.HTTPFile, request *models.HTTPRequest, err error) *models.HTTPResponse {
+	resp := &models.HTTPResponse{Request: request, Error: err}
+	if e.onResponse != nil {
+		e.onResponse(httpFile, resp)
+	}
+	return resp
+}
+
+// targetRequests 确定要执行的请求：指定了选择时为选中的请求，否则为文件中的全部请求
+func targetRequests(httpFile *models.HTTPFile, selector *Selector) ([]*models.HTTPRequest, error) {
+	targets := selector.Select(httpFile)
+	if selector != nil && len(targets) == 0 {
+		return nil, fmt.Errorf("没有与 '%s' 匹配的请求", selector)
+	}
+	return targets, nil
+}
+
+// clientFor 返回执行请求使用的HTTP客户端，请求中的指令会覆盖默认设置
+func (e *Executor) clientFor(req *models.HTTPRequest) *http.Client {
+	if !req.NoRedirect && !req.NoCookieJar && req.Timeout == 0 && req.ConnectionTimeout == 0 {
+		return e.client
+	}
+
+	client := *e.client
+	if req.NoRedirect {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	if req.NoCookieJar {
+		client.Jar = nil
+	}
+	if req.Timeout > 0 {
+		client.Timeout = req.Timeout
+	}
+	if req.ConnectionTimeout > 0 {
+		client.Transport = e.transportFor(req.ConnectionTimeout)
+	}
+	return &client
+}
+
+// transportFor 返回使用指定连接超时时间的Transport，相同超时时间的请求共享连接池
+func (e *Executor) transportFor(connectionTimeout time.Duration) *http.Transport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.transports == nil {
+		e.transports = make(map[time.Duration]*http.Transport)
+	}
+	if transport, ok := e.transports[connectionTimeout]; ok {
+		return transport
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: connectionTimeout, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = connectionTimeout
+	e.transports[connectionTimeout] = transport
+	return transport
+}
+
 // createHTTPRequest 创建HTTP请求
 func (e *Executor) createHTTPRequest(req *models.HTTPRequest) (*http.Request, error) {
 	var bodyReader io.Reader
@@ -232,11 +547,40 @@ func (e *Executor) createHTTPRequest(req *models.HTTPRequest) (*http.Request, er
 		return nil, fmt.Errorf("无效的URL: %s（必须以http://或https://开头）", urlStr)
 	}
 
+	// multipart请求体和来自文件的请求体在发送时流式读取，避免将大文件读入内存
+	var contentLength int64 = -1
+	if len(req.MultipartParts) > 0 {
+		body, length, err := newMultipartBody(req)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = body
+		contentLength = length
+	} else if req.BodyFile != "" {
+		file, err := os.Open(req.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("无法打开请求体文件: %w", err)
+		}
+		if info, err := file.Stat(); err == nil {
+			contentLength = info.Size()
+		}
+		bodyReader = file
+	}
+
 	// 创建HTTP请求
 	httpReq, err := http.NewRequest(req.Method, urlStr, bodyReader)
 	if err != nil {
+		if closer, ok := bodyReader.(io.Closer); ok {
+			closer.Close()
+		}
 		return nil, err
 	}
+	if contentLength > 0 {
+		httpReq.ContentLength = contentLength
+	} else if contentLength == 0 {
+		httpReq.Body.Close()
+		httpReq.Body = http.NoBody
+	}
 
 	// 设置请求头
 	for name, values := range req.Headers {
@@ -253,8 +597,80 @@ func (e *Executor) createHTTPRequest(req *models.HTTPRequest) (*http.Request, er
 	return httpReq, nil
 }
 
-// PrintResponse 打印响应结果
-func PrintResponse(resp *models.HTTPResponse) {
+// saveResponse 将响应体保存到文件，返回实际写入的文件路径
+// 不覆盖时如果文件已存在，会在文件名后追加序号，如 user-1.json、user-2.json。
+// 并行执行时多个请求可能同时保存到同一路径，文件名在锁内选择并以O_EXCL创建，不会互相覆盖
+func (e *Executor) saveResponse(path string, overwrite bool, body []byte) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	if overwrite {
+		return path, writeFileAtomic(path, body)
+	}
+
+	file, path, err := e.createUnique(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(body); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// createUnique 创建一个不存在的文件：先尝试原路径，已存在时依次尝试追加序号的路径
+func (e *Executor) createUnique(path string) (*os.File, string, error) {
+	e.saveMu.Lock()
+	defer e.saveMu.Unlock()
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	candidate := path
+	for i := e.saveSuffix[path]; ; i++ {
+		if i > 0 {
+			candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		file, err := os.OpenFile(candidate, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			e.saveSuffix[path] = i + 1
+			return file, candidate, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, "", err
+		}
+	}
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名，并发覆盖同一文件时不会写出交错的内容
+func writeFileAtomic(path string, body []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// PrintResponse 打印响应结果，按opts格式化响应体
+func PrintResponse(resp *models.HTTPResponse, opts format.Options) {
 	if resp.Error != nil {
 		fmt.Printf("请求失败: %v\n", resp.Error)
 		fmt.Printf("请求耗时: %d ms\n", resp.Time)
@@ -274,17 +690,56 @@ func PrintResponse(resp *models.HTTPResponse) {
 	// 打印空行和响应体
 	if len(resp.Body) > 0 {
 		fmt.Println()
+		fmt.Println(format.Body(resp.Headers.Get("Content-Type"), resp.BodyString, opts))
+	}
+
+	// 打印请求耗时
+	fmt.Printf("\n请求耗时: %d ms\n", resp.Time)
+
+	if resp.OutputFile != "" {
+		fmt.Printf("响应已保存到文件: %s\n", resp.OutputFile)
+	}
+
+	if resp.ScriptError != nil {
+		fmt.Printf("脚本错误: %v\n", resp.ScriptError)
+	}
+}
 
-		// 检查Content-Type以决定如何格式化输出
-		contentType := resp.Headers.Get("Content-Type")
-		if strings.Contains(contentType, "application/json") {
-			// 尝试美化JSON输出（这是简化版）
-			fmt.Println(resp.BodyString)
+// printTestResults 打印单个响应的测试结果
+func printTestResults(resp *models.HTTPResponse) {
+	for _, t := range resp.Tests {
+		if t.Passed {
+			fmt.Printf("  ✓ %s\n", t.Name)
 		} else {
-			fmt.Println(resp.BodyString)
+			fmt.Printf("  ✗ %s: %s\n", t.Name, t.Message)
 		}
 	}
+}
 
-	// 打印请求耗时
-	fmt.Printf("\n请求耗时: %d ms\n", resp.Time)
+// PrintTestSummary 打印所有响应的测试汇总，没有任何测试时不输出
+func PrintTestSummary(responses []*models.HTTPResponse) {
+	total, failed := 0, 0
+	for _, resp := range responses {
+		total += len(resp.Tests)
+		failed += resp.FailedTests()
+		if resp.ScriptError != nil {
+			total++
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	fmt.Println("\n测试结果:")
+	for _, resp := range responses {
+		if len(resp.Tests) == 0 && resp.ScriptError == nil {
+			continue
+		}
+		fmt.Printf("%s\n", resp.Request.DisplayName())
+		printTestResults(resp)
+		if resp.ScriptError != nil {
+			fmt.Printf("  ✗ %v\n", resp.ScriptError)
+		}
+	}
+	fmt.Printf("\n共 %d 个测试，通过 %d 个，失败 %d 个\n", total, total-failed, failed)
 }
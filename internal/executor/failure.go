@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// FailureKind 请求失败的类型，数值越小越严重
+type FailureKind int
+
+const (
+	FailureNone      FailureKind = iota // 没有失败
+	FailureTransport                    // 请求没有完成：网络错误、超时、预请求脚本失败等
+	FailureStatus                       // 响应状态码匹配--fail-on-status
+	FailureAssertion                    // 测试或断言失败、响应处理脚本出错
+)
+
+// StatusPatterns 表示一组状态码，如"4xx,5xx"或"404,500-599"
+type StatusPatterns [][2]int
+
+// ParseStatusPatterns 解析逗号分隔的状态码，支持"4xx"、"404"和"500-599"三种写法，"none"表示不把任何状态码视为失败
+func ParseStatusPatterns(spec string) (StatusPatterns, error) {
+	patterns := make(StatusPatterns, 0)
+	if strings.EqualFold(strings.TrimSpace(spec), "none") {
+		return patterns, nil
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+
+		switch {
+		case len(item) == 3 && strings.HasSuffix(item, "xx") && item[0] >= '1' && item[0] <= '5':
+			base := int(item[0]-'0') * 100
+			patterns = append(patterns, [2]int{base, base + 99})
+
+		case strings.Contains(item, "-"):
+			from, to, _ := strings.Cut(item, "-")
+			low, err1 := strconv.Atoi(strings.TrimSpace(from))
+			high, err2 := strconv.Atoi(strings.TrimSpace(to))
+			if err1 != nil || err2 != nil || low > high {
+				return nil, fmt.Errorf("无效的状态码范围 '%s'", item)
+			}
+			patterns = append(patterns, [2]int{low, high})
+
+		default:
+			code, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, fmt.Errorf("无效的状态码 '%s'，支持4xx、404、500-599等写法", item)
+			}
+			patterns = append(patterns, [2]int{code, code})
+		}
+	}
+	return patterns, nil
+}
+
+// Match 判断状态码是否匹配
+func (p StatusPatterns) Match(code int) bool {
+	for _, r := range p {
+		if code >= r[0] && code <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Classify 判断响应的失败类型，同时有多种失败时返回最严重的一种
+func Classify(resp *models.HTTPResponse, failOnStatus StatusPatterns) FailureKind {
+	switch {
+	case resp.Error != nil:
+		return FailureTransport
+	case failOnStatus.Match(resp.StatusCode):
+		return FailureStatus
+	case resp.FailedTests() > 0:
+		return FailureAssertion
+	}
+	return FailureNone
+}
+
+// WorstFailure 返回一组响应中最严重的失败类型
+func WorstFailure(responses []*models.HTTPResponse, failOnStatus StatusPatterns) FailureKind {
+	worst := FailureNone
+	for _, resp := range responses {
+		kind := Classify(resp, failOnStatus)
+		if kind != FailureNone && (worst == FailureNone || kind < worst) {
+			worst = kind
+		}
+	}
+	return worst
+}
@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestParseStatusPatterns(t *testing.T) {
+	tests := []struct {
+		spec     string
+		match    []int
+		notMatch []int
+		wantErr  string
+	}{
+		{spec: "4xx,5xx", match: []int{400, 404, 499, 500, 599}, notMatch: []int{200, 302, 399, 600}},
+		{spec: "404", match: []int{404}, notMatch: []int{400, 405}},
+		{spec: "500-503, 418", match: []int{418, 500, 503}, notMatch: []int{499, 504}},
+		{spec: " 4XX ", match: []int{400}, notMatch: []int{500}},
+		{spec: "", notMatch: []int{404, 500}},
+		{spec: "none", notMatch: []int{404, 500}},
+		{spec: "None", notMatch: []int{500}},
+		{spec: "abc", wantErr: "无效的状态码 'abc'"},
+		{spec: "6xx", wantErr: "无效的状态码"},
+		{spec: "503-500", wantErr: "无效的状态码范围"},
+		{spec: "500-x", wantErr: "无效的状态码范围"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			patterns, err := ParseStatusPatterns(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseStatusPatterns() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStatusPatterns() 错误 = %v", err)
+			}
+			for _, code := range tt.match {
+				if !patterns.Match(code) {
+					t.Errorf("Match(%d) = false, 期望 true", code)
+				}
+			}
+			for _, code := range tt.notMatch {
+				if patterns.Match(code) {
+					t.Errorf("Match(%d) = true, 期望 false", code)
+				}
+			}
+		})
+	}
+}
+
+// newFailingFile 创建依次请求A、B、C的文件，B的响应无法保存，执行B时出错
+func newFailingFile(t *testing.T, server *testServer) *models.HTTPFile {
+	t.Helper()
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	httpFile := models.NewHTTPFile("test.http")
+	for _, name := range []string{"A", "B", "C"} {
+		req := &models.HTTPRequest{Name: name, Method: "GET", Headers: make(http.Header)}
+		req.URL, _ = url.Parse(server.URL + "/" + name)
+		if name == "B" {
+			req.ResponseOutput = filepath.Join(blocker, "b.json")
+		}
+		httpFile.AddRequest(req)
+	}
+	return httpFile
+}
+
+func TestExecuteFilePartialResponses(t *testing.T) {
+	server := newTestServer(t)
+	httpFile := newFailingFile(t, server)
+
+	e := NewExecutor(false)
+	var callbacks []string
+	e.SetResponseCallback(func(_ *models.HTTPFile, resp *models.HTTPResponse) {
+		callbacks = append(callbacks, resp.Request.Name)
+	})
+	responses, err := e.ExecuteFile(httpFile, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "执行请求 'B' 失败: 保存响应失败") {
+		t.Fatalf("ExecuteFile() 错误 = %v", err)
+	}
+
+	// 返回已执行的请求的响应，出错的请求记录为带有错误的响应，之后的请求不再执行
+	if got := responseSummary(responses); !reflect.DeepEqual(got, []string{"A 200", "B 0"}) {
+		t.Errorf("响应 = %v, 期望 [A 200 B 0]", got)
+	}
+	if responses[1].Error == nil || responses[1].Error.Error() != err.Error() {
+		t.Errorf("B 的错误 = %v, 期望 %v", responses[1].Error, err)
+	}
+	if Classify(responses[1], nil) != FailureTransport {
+		t.Errorf("Classify() = %v, 期望 FailureTransport", Classify(responses[1], nil))
+	}
+	if !reflect.DeepEqual(callbacks, []string{"A", "B"}) {
+		t.Errorf("回调 = %v, 期望 [A B]", callbacks)
+	}
+	if server.requests("/C") != 0 {
+		t.Error("出错后不应执行后续请求")
+	}
+}
+
+func TestExecuteParallelPartialResponses(t *testing.T) {
+	server := newTestServer(t)
+	httpFile := newFailingFile(t, server)
+
+	responses, stats, err := NewExecutor(false).ExecuteParallel(httpFile, ParallelOptions{Concurrency: 1})
+	if err == nil || !strings.Contains(err.Error(), "执行请求 'B' 失败") {
+		t.Fatalf("ExecuteParallel() 错误 = %v", err)
+	}
+	// 只有一个工作协程，B出错时最多已经分发了C
+	got := responseSummary(responses)
+	if len(got) < 2 || !reflect.DeepEqual(got[:2], []string{"A 200", "B 0"}) || responses[1].Error == nil {
+		t.Errorf("响应 = %v, 期望以 [A 200 B 0] 开头", got)
+	}
+	if stats == nil || stats.Total != len(responses) || stats.Failed < 1 {
+		t.Errorf("统计 = %+v", stats)
+	}
+}
@@ -0,0 +1,379 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+	"github.com/shellus/jhttp/internal/stats"
+)
+
+// defaultLoadIdleConns 不限制并发时每个主机保留的空闲连接数
+const defaultLoadIdleConns = 100
+
+// LoadStage 表示压测的一个阶段：在Duration内，目标速率从上一阶段结束时的速率线性变化到Target
+// 第一个阶段从0开始；Duration为0的阶段表示立即切换到Target
+type LoadStage struct {
+	Duration time.Duration // 阶段持续时间
+	Target   float64       // 阶段结束时的目标速率（请求/秒）
+}
+
+// LoadOptions 压测选项
+type LoadOptions struct {
+	Selector         *Selector     // 压测的请求，为nil时按文件顺序轮流发送全部请求
+	Env              string        // 使用的环境
+	Stages           []LoadStage   // 压测阶段
+	MaxInFlight      int           // 最多同时进行的请求数，0表示不限制
+	ProgressInterval time.Duration // 输出进度的间隔，0表示不输出
+}
+
+// LoadResult 压测结果
+// Latency从计划发送时间开始计算：服务端变慢导致请求排队或发送推迟时，等待时间也计入延迟，避免协调遗漏（coordinated omission）
+type LoadResult struct {
+	Sent        int64            // 已发送的请求数
+	Succeeded   int64            // 成功数（没有网络错误且状态码小于400）
+	Failed      int64            // 失败数
+	Elapsed     time.Duration    // 执行总耗时
+	Latency     *stats.Histogram // 从计划发送时间到收到响应的延迟
+	ServiceTime *stats.Histogram // 实际发送到收到响应的耗时
+	StatusCodes map[int]int64    // 各状态码的数量
+	Errors      map[string]int64 // 各类错误的数量
+}
+
+// Throughput 返回实际吞吐量（每秒完成的请求数）
+func (r *LoadResult) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Succeeded+r.Failed) / r.Elapsed.Seconds()
+}
+
+// ParseLoadStages 解析压测阶段，格式为逗号分隔的"持续时间:目标速率"，例如"30s:50,2m:50,10s:0"
+func ParseLoadStages(spec string) ([]LoadStage, error) {
+	stages := make([]LoadStage, 0)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		durationText, targetText, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("无效的压测阶段 '%s'，格式应为 持续时间:目标速率", item)
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(durationText))
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("无效的压测阶段持续时间 '%s'", durationText)
+		}
+		target, err := strconv.ParseFloat(strings.TrimSpace(targetText), 64)
+		if err != nil || target < 0 {
+			return nil, fmt.Errorf("无效的压测阶段目标速率 '%s'", targetText)
+		}
+		stages = append(stages, LoadStage{Duration: duration, Target: target})
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("没有指定压测阶段")
+	}
+	return stages, nil
+}
+
+// loadProfile 根据压测阶段计算任意时刻的目标速率
+type loadProfile []LoadStage
+
+// total 返回压测总时长
+func (p loadProfile) total() time.Duration {
+	var total time.Duration
+	for _, stage := range p {
+		total += stage.Duration
+	}
+	return total
+}
+
+// rateAt 返回t时刻的目标速率
+func (p loadProfile) rateAt(t time.Duration) float64 {
+	var start time.Duration
+	previous := 0.0
+	for _, stage := range p {
+		if stage.Duration > 0 && t < start+stage.Duration {
+			progress := float64(t-start) / float64(stage.Duration)
+			return previous + (stage.Target-previous)*progress
+		}
+		start += stage.Duration
+		previous = stage.Target
+	}
+	return 0
+}
+
+// loadRecorder 汇总压测过程中的结果，并发安全
+type loadRecorder struct {
+	mu       sync.Mutex
+	result   *LoadResult
+	inFlight int64
+	window   *stats.Histogram // 当前进度区间内的延迟
+	finished int64            // 当前进度区间内完成的请求数
+	failed   int64            // 当前进度区间内失败的请求数
+}
+
+// record 记录一次请求的结果
+func (r *loadRecorder) record(resp *models.HTTPResponse, err error, latency, serviceTime time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.inFlight--
+	r.finished++
+	r.result.Latency.Record(latency)
+	r.result.ServiceTime.Record(serviceTime)
+	r.window.Record(latency)
+
+	switch {
+	case err != nil:
+		r.result.Errors[err.Error()]++
+	case resp.Error != nil:
+		r.result.Errors[resp.Error.Error()]++
+	default:
+		r.result.StatusCodes[resp.StatusCode]++
+	}
+	if err == nil && resp.Error == nil && resp.StatusCode < 400 {
+		r.result.Succeeded++
+	} else {
+		r.result.Failed++
+		r.failed++
+	}
+}
+
+// ExecuteLoad 按开放模型对请求进行压测：按照目标速率的计划时间发送请求，不等待之前的请求完成，
+// 服务端变慢时并发请求数随之增加。压测前先执行一次被压测请求依赖的前置请求，
+// 压测中的请求只记录状态码和耗时，见sendForLoad
+func (e *Executor) ExecuteLoad(httpFile *models.HTTPFile, options LoadOptions) (*LoadResult, error) {
+	profile := loadProfile(options.Stages)
+	total := profile.total()
+	if total <= 0 {
+		return nil, fmt.Errorf("压测总时长必须大于0")
+	}
+
+	targets, err := targetRequests(httpFile, options.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("没有可执行的请求")
+	}
+	if err := e.executePrerequisites(httpFile, targets, options.Selector, options.Env); err != nil {
+		return nil, err
+	}
+
+	recorder := &loadRecorder{
+		result: &LoadResult{
+			Latency:     stats.NewHistogram(),
+			ServiceTime: stats.NewHistogram(),
+			StatusCodes: make(map[int]int64),
+			Errors:      make(map[string]int64),
+		},
+		window: stats.NewHistogram(),
+	}
+
+	var slots chan struct{}
+	if options.MaxInFlight > 0 {
+		slots = make(chan struct{}, options.MaxInFlight)
+		e.usePooledTransport(options.MaxInFlight)
+	} else {
+		e.usePooledTransport(defaultLoadIdleConns)
+	}
+
+	start := time.Now()
+	stopProgress := make(chan struct{})
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		if options.ProgressInterval <= 0 {
+			<-stopProgress
+			return
+		}
+		ticker := time.NewTicker(options.ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopProgress:
+				return
+			case now := <-ticker.C:
+				recorder.printProgress(now.Sub(start), profile.rateAt(now.Sub(start)), options.ProgressInterval)
+			}
+		}
+	}()
+
+	// 以1毫秒为步长累计目标速率的积分，累计请求数每增加1就安排发送一个请求
+	const step = time.Millisecond
+	var wg sync.WaitGroup
+	var planned float64
+	next := 0
+	for t := time.Duration(0); t < total; t += step {
+		planned += profile.rateAt(t) * step.Seconds()
+		for planned >= 1 {
+			planned--
+			intended := start.Add(t + step)
+			if wait := time.Until(intended); wait > 0 {
+				time.Sleep(wait)
+			}
+
+			req := targets[next%len(targets)]
+			next++
+
+			recorder.mu.Lock()
+			recorder.result.Sent++
+			recorder.inFlight++
+			recorder.mu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if slots != nil {
+					slots <- struct{}{}
+					defer func() { <-slots }()
+				}
+				sendTime := time.Now()
+				resp, err := e.sendForLoad(httpFile, req, options.Env)
+				done := time.Now()
+				recorder.record(resp, err, done.Sub(intended), done.Sub(sendTime))
+			}()
+		}
+	}
+
+	wg.Wait()
+	close(stopProgress)
+	<-progressDone
+
+	result := recorder.result
+	result.Elapsed = time.Since(start)
+	return result, nil
+}
+
+// sendForLoad 压测时发送一个请求，只记录状态码和耗时
+// 不保存响应（>>）、不执行响应处理脚本和断言、不更新命名请求的响应，也不输出详细信息，
+// 避免这些处理影响延迟统计，或在压测中写入大量文件
+func (e *Executor) sendForLoad(httpFile *models.HTTPFile, request *models.HTTPRequest, env string) (*models.HTTPResponse, error) {
+	resolvedReq, req, failed, err := e.prepare(httpFile, request, env)
+	if err != nil || failed != nil {
+		return failed, err
+	}
+
+	client := e.clientFor(resolvedReq)
+	ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		errorType, errorMessage := describeError(err, req)
+		return &models.HTTPResponse{
+			Request: resolvedReq,
+			Error:   fmt.Errorf("%s: %s", errorType, errorMessage),
+			Time:    time.Since(startTime).Milliseconds(),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	// 读完响应体才能复用连接，响应体本身不保留
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return nil, fmt.Errorf("读取响应体失败: %w", err)
+	}
+	return &models.HTTPResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Time:       time.Since(startTime).Milliseconds(),
+		Request:    resolvedReq,
+	}, nil
+}
+
+// executePrerequisites 按依赖顺序执行targets依赖的前置请求，每个只执行一次
+func (e *Executor) executePrerequisites(httpFile *models.HTTPFile, targets []*models.HTTPRequest, selector *Selector, env string) error {
+	ordered, err := buildDependencyGraph(httpFile, env).order(targets)
+	if err != nil {
+		return err
+	}
+	if err := selector.checkPrerequisites(ordered); err != nil {
+		return err
+	}
+	isTarget := make(map[*models.HTTPRequest]bool, len(targets))
+	for _, req := range targets {
+		isTarget[req] = true
+	}
+
+	for _, req := range ordered {
+		if isTarget[req] || e.isExecuted(req) {
+			continue
+		}
+		if e.verbose {
+			fmt.Printf("\n===== 执行前置请求: %s =====\n", req.Name)
+		}
+		if _, err := e.Execute(httpFile, req, env); err != nil {
+			return fmt.Errorf("执行请求 '%s' 失败: %w", req.DisplayName(), err)
+		}
+	}
+	return nil
+}
+
+// printProgress 打印一行进度，延迟分布为本区间内完成的请求
+func (r *loadRecorder) printProgress(elapsed time.Duration, rate float64, interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Printf("[%6s] 目标速率 %.1f/s，实际 %.1f/s，已发送 %d，进行中 %d，失败 %d，p50 %s，p99 %s\n",
+		elapsed.Truncate(time.Second), rate, float64(r.finished)/interval.Seconds(),
+		r.result.Sent, r.inFlight, r.failed,
+		formatLatency(r.window.Percentile(50)), formatLatency(r.window.Percentile(99)))
+
+	r.window.Reset()
+	r.finished = 0
+	r.failed = 0
+}
+
+// PrintLoadResult 打印压测结果
+func PrintLoadResult(result *LoadResult) {
+	completed := result.Succeeded + result.Failed
+	successRate := 0.0
+	if completed > 0 {
+		successRate = float64(result.Succeeded) * 100 / float64(completed)
+	}
+
+	fmt.Println("\n压测结果:")
+	fmt.Printf("  请求总数: %d，成功: %d，失败: %d，成功率: %.2f%%\n",
+		result.Sent, result.Succeeded, result.Failed, successRate)
+	fmt.Printf("  总耗时: %s，吞吐量: %.2f 请求/秒\n", result.Elapsed.Truncate(time.Millisecond), result.Throughput())
+	printHistogram("  延迟（从计划发送时间计算）", result.Latency)
+	printHistogram("  服务端耗时", result.ServiceTime)
+
+	if len(result.StatusCodes) > 0 {
+		codes := make([]string, 0, len(result.StatusCodes))
+		for code, count := range result.StatusCodes {
+			codes = append(codes, fmt.Sprintf("%d×%d", code, count))
+		}
+		sort.Strings(codes)
+		fmt.Printf("  状态码: %s\n", strings.Join(codes, "，"))
+	}
+
+	messages := make([]string, 0, len(result.Errors))
+	for message := range result.Errors {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	for _, message := range messages {
+		fmt.Printf("  错误 ×%d: %s\n", result.Errors[message], message)
+	}
+}
+
+// printHistogram 打印直方图的分布
+func printHistogram(title string, h *stats.Histogram) {
+	fmt.Printf("%s: 最小 %s，平均 %s，p50 %s，p90 %s，p99 %s，最大 %s\n", title,
+		formatLatency(h.Min()), formatLatency(h.Mean()), formatLatency(h.Percentile(50)),
+		formatLatency(h.Percentile(90)), formatLatency(h.Percentile(99)), formatLatency(h.Max()))
+}
+
+// formatLatency 以毫秒格式化耗时，保留一位小数
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,188 @@
+package executor
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestParseLoadStages(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []LoadStage
+		wantErr string
+	}{
+		{spec: "30s:50", want: []LoadStage{{30 * time.Second, 50}}},
+		{spec: " 30s:50 , 2m:50,10s:0 ", want: []LoadStage{{30 * time.Second, 50}, {2 * time.Minute, 50}, {10 * time.Second, 0}}},
+		{spec: "0s:20,1m:20", want: []LoadStage{{0, 20}, {time.Minute, 20}}},
+		{spec: "1s:0.5", want: []LoadStage{{time.Second, 0.5}}},
+		{spec: "", wantErr: "没有指定压测阶段"},
+		{spec: "30s", wantErr: "格式应为 持续时间:目标速率"},
+		{spec: "30:50", wantErr: "无效的压测阶段持续时间"},
+		{spec: "-1s:50", wantErr: "无效的压测阶段持续时间"},
+		{spec: "30s:abc", wantErr: "无效的压测阶段目标速率"},
+		{spec: "30s:-1", wantErr: "无效的压测阶段目标速率"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			stages, err := ParseLoadStages(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseLoadStages() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLoadStages() 错误 = %v", err)
+			}
+			if !reflect.DeepEqual(stages, tt.want) {
+				t.Errorf("ParseLoadStages() = %v, 期望 %v", stages, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfileRate(t *testing.T) {
+	// 10秒内从0升到100，保持10秒，立即切换到20并保持5秒，最后5秒降到0
+	profile := loadProfile{
+		{10 * time.Second, 100},
+		{10 * time.Second, 100},
+		{0, 20},
+		{5 * time.Second, 20},
+		{5 * time.Second, 0},
+	}
+	if total := profile.total(); total != 30*time.Second {
+		t.Errorf("total() = %v, 期望 30s", total)
+	}
+
+	tests := []struct {
+		t    time.Duration
+		want float64
+	}{
+		{0, 0},
+		{2500 * time.Millisecond, 25},
+		{5 * time.Second, 50},
+		{10 * time.Second, 100},
+		{15 * time.Second, 100},
+		{20 * time.Second, 20},
+		{24 * time.Second, 20},
+		{25 * time.Second, 20},
+		{27500 * time.Millisecond, 10},
+		{30 * time.Second, 0},
+		{time.Minute, 0},
+	}
+	for _, tt := range tests {
+		if got := profile.rateAt(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("rateAt(%v) = %v, 期望 %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages string
+		min    int64 // 速率积分的浮点误差可能少发一个请求
+		max    int64
+	}{
+		{name: "恒定速率", stages: "0s:200,100ms:200", min: 19, max: 20},
+		{name: "线性增长", stages: "200ms:100", min: 9, max: 10},
+		{name: "速率为0", stages: "100ms:0", min: 0, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			httpFile := parseTestFile(t, server, parallelTestFile)
+			selector, err := ParseSelector([]string{"用户"}, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stages, err := ParseLoadStages(tt.stages)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := NewExecutor(false).ExecuteLoad(httpFile, LoadOptions{Selector: selector, Stages: stages})
+			if err != nil {
+				t.Fatalf("ExecuteLoad() 错误 = %v", err)
+			}
+			if result.Sent < tt.min || result.Sent > tt.max {
+				t.Errorf("Sent = %d, 期望 %d~%d", result.Sent, tt.min, tt.max)
+			}
+			// 前置请求只执行一次，压测中的请求使用它设置的token
+			if server.requests("/login") != 1 {
+				t.Errorf("登录请求数 = %d, 期望 1", server.requests("/login"))
+			}
+			if int64(server.requests("/user")) != result.Sent || result.StatusCodes[200] != result.Sent || result.Succeeded != result.Sent {
+				t.Errorf("结果 = 发送 %d, 服务端收到 %d, 状态码 %v", result.Sent, server.requests("/user"), result.StatusCodes)
+			}
+			if result.Latency.Count() != result.Sent || result.ServiceTime.Count() != result.Sent {
+				t.Errorf("延迟记录数 = %d/%d, 期望 %d", result.Latency.Count(), result.ServiceTime.Count(), result.Sent)
+			}
+		})
+	}
+}
+
+func TestExecuteLoadOpenModel(t *testing.T) {
+	// 服务端每个请求耗时50ms，限制同时只有1个请求时后面的请求需要排队
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	httpFile := models.NewHTTPFile("test.http")
+	for _, path := range []string{"/ok", "/missing"} {
+		req := &models.HTTPRequest{Method: "GET", Headers: make(http.Header)}
+		req.URL, _ = url.Parse(server.URL + path)
+		httpFile.AddRequest(req)
+	}
+	stages, err := ParseLoadStages("0s:100,100ms:100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewExecutor(false).ExecuteLoad(httpFile, LoadOptions{Stages: stages, MaxInFlight: 1})
+	if err != nil {
+		t.Fatalf("ExecuteLoad() 错误 = %v", err)
+	}
+
+	// 请求按计划时间发出，不等待之前的请求完成；排队时间计入延迟，但不计入服务时间
+	if result.Sent < 9 || result.Sent > 10 {
+		t.Fatalf("Sent = %d, 期望 9~10", result.Sent)
+	}
+	if result.Latency.Max() < 300*time.Millisecond {
+		t.Errorf("最大延迟 = %v, 期望包含排队时间", result.Latency.Max())
+	}
+	if result.ServiceTime.Max() > 200*time.Millisecond {
+		t.Errorf("最大服务时间 = %v, 不应包含排队时间", result.ServiceTime.Max())
+	}
+	// 没有指定选择时轮流发送全部请求
+	if result.StatusCodes[200]+result.StatusCodes[404] != result.Sent || result.StatusCodes[404] < result.Sent/2 {
+		t.Errorf("状态码 = %v", result.StatusCodes)
+	}
+	if result.Failed != result.StatusCodes[404] || result.Succeeded != result.StatusCodes[200] {
+		t.Errorf("成功/失败 = %d/%d, 状态码 %v", result.Succeeded, result.Failed, result.StatusCodes)
+	}
+}
+
+func TestExecuteLoadErrors(t *testing.T) {
+	server := newTestServer(t)
+	httpFile := parseTestFile(t, server, parallelTestFile)
+
+	_, err := NewExecutor(false).ExecuteLoad(httpFile, LoadOptions{Stages: []LoadStage{{0, 100}}})
+	if err == nil || !strings.Contains(err.Error(), "压测总时长必须大于0") {
+		t.Errorf("ExecuteLoad() 错误 = %v", err)
+	}
+}
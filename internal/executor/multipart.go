@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// multipartBody 按请求中的各个部分构建multipart请求体
+// 文件部分在发送时流式读取，返回的长度可直接作为Content-Length
+type multipartBody struct {
+	io.Reader
+	files []*os.File
+}
+
+// Close 关闭请求体中打开的所有文件
+func (b *multipartBody) Close() error {
+	for _, f := range b.files {
+		f.Close()
+	}
+	return nil
+}
+
+// newMultipartBody 创建multipart请求体，分隔符取自请求的Content-Type
+func newMultipartBody(req *models.HTTPRequest) (*multipartBody, int64, error) {
+	_, params, err := mime.ParseMediaType(req.Headers.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		return nil, 0, fmt.Errorf("multipart请求缺少boundary")
+	}
+	boundary := params["boundary"]
+
+	body := &multipartBody{}
+	readers := make([]io.Reader, 0, len(req.MultipartParts)*2+1)
+	var length int64
+
+	addText := func(text string) {
+		readers = append(readers, strings.NewReader(text))
+		length += int64(len(text))
+	}
+
+	for i, part := range req.MultipartParts {
+		var head strings.Builder
+		if i > 0 {
+			head.WriteString("\r\n")
+		}
+		head.WriteString("--" + boundary + "\r\n")
+
+		names := make([]string, 0, len(part.Headers))
+		for name := range part.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range part.Headers[name] {
+				fmt.Fprintf(&head, "%s: %s\r\n", name, value)
+			}
+		}
+		head.WriteString("\r\n")
+		addText(head.String())
+
+		if part.FilePath == "" {
+			addText(part.Body)
+			continue
+		}
+
+		file, err := os.Open(part.FilePath)
+		if err != nil {
+			body.Close()
+			return nil, 0, fmt.Errorf("无法打开multipart文件: %w", err)
+		}
+		body.files = append(body.files, file)
+
+		info, err := file.Stat()
+		if err != nil {
+			body.Close()
+			return nil, 0, fmt.Errorf("无法读取multipart文件信息: %w", err)
+		}
+		readers = append(readers, file)
+		length += info.Size()
+	}
+	addText("\r\n--" + boundary + "--\r\n")
+
+	body.Reader = io.MultiReader(readers...)
+	return body, length, nil
+}
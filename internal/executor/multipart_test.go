@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestNewMultipartBody(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "a.png")
+	imageData := "\x89PNG\r\n\x1a\n二进制内容"
+	if err := os.WriteFile(image, []byte(imageData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// part 创建multipart请求的一个部分
+	part := func(disposition, contentType, body, file string) *models.MultipartPart {
+		p := &models.MultipartPart{Headers: http.Header{"Content-Disposition": []string{disposition}}, Body: body, FilePath: file}
+		if contentType != "" {
+			p.Headers.Set("Content-Type", contentType)
+		}
+		return p
+	}
+
+	type wantPart struct {
+		name, filename, contentType, body string
+	}
+	tests := []struct {
+		name  string
+		parts []*models.MultipartPart
+		want  []wantPart
+	}{
+		{
+			name:  "文本部分",
+			parts: []*models.MultipartPart{part(`form-data; name="title"`, "", "你好\n世界", "")},
+			want:  []wantPart{{name: "title", body: "你好\n世界"}},
+		},
+		{
+			name: "文本和文件部分",
+			parts: []*models.MultipartPart{
+				part(`form-data; name="title"`, "", "标题", ""),
+				part(`form-data; name="file"; filename="a.png"`, "image/png", "", image),
+				part(`form-data; name="note"`, "text/plain", "", ""),
+			},
+			want: []wantPart{
+				{name: "title", body: "标题"},
+				{name: "file", filename: "a.png", contentType: "image/png", body: imageData},
+				{name: "note", contentType: "text/plain"},
+			},
+		},
+		{
+			name:  "空文件",
+			parts: []*models.MultipartPart{part(`form-data; name="file"; filename="empty.txt"`, "", "", empty)},
+			want:  []wantPart{{name: "file", filename: "empty.txt"}},
+		},
+		{
+			name:  "没有部分",
+			parts: nil,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.HTTPRequest{
+				Headers:        http.Header{"Content-Type": []string{"multipart/form-data; boundary=WebBoundary"}},
+				MultipartParts: tt.parts,
+			}
+			body, length, err := newMultipartBody(req)
+			if err != nil {
+				t.Fatalf("newMultipartBody() 错误 = %v", err)
+			}
+			defer body.Close()
+
+			data, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(data)) != length {
+				t.Errorf("长度 = %d, 实际读取 %d 字节", length, len(data))
+			}
+
+			reader := multipart.NewReader(strings.NewReader(string(data)), "WebBoundary")
+			for i, want := range tt.want {
+				p, err := reader.NextPart()
+				if err != nil {
+					t.Fatalf("第 %d 部分: %v", i+1, err)
+				}
+				content, _ := io.ReadAll(p)
+				if p.FormName() != want.name || p.FileName() != want.filename ||
+					p.Header.Get("Content-Type") != want.contentType || string(content) != want.body {
+					t.Errorf("第 %d 部分 = %s %q %q %q, 期望 %+v",
+						i+1, p.FormName(), p.FileName(), p.Header.Get("Content-Type"), content, want)
+				}
+			}
+			if _, err := reader.NextPart(); err != io.EOF {
+				t.Errorf("多余的部分: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewMultipartBodyErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		file        string
+		wantErr     string
+	}{
+		{"缺少boundary", "multipart/form-data", "", "缺少boundary"},
+		{"无效的Content-Type", "", "", "缺少boundary"},
+		{"文件不存在", "multipart/form-data; boundary=b", filepath.Join(t.TempDir(), "missing.png"), "无法打开multipart文件"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.HTTPRequest{
+				Headers:        http.Header{"Content-Type": []string{tt.contentType}},
+				MultipartParts: []*models.MultipartPart{{Headers: make(http.Header), FilePath: tt.file}},
+			}
+			_, _, err := newMultipartBody(req)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("newMultipartBody() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMultipartBoundaryParam(t *testing.T) {
+	// 分隔符带引号时使用引号中的内容
+	req := &models.HTTPRequest{
+		Headers:        http.Header{"Content-Type": []string{`multipart/form-data; boundary="a b"`}},
+		MultipartParts: []*models.MultipartPart{{Headers: make(http.Header), Body: "x"}},
+	}
+	body, _, err := newMultipartBody(req)
+	if err != nil {
+		t.Fatalf("newMultipartBody() 错误 = %v", err)
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	if !strings.HasPrefix(string(data), "--a b\r\n") || !strings.HasSuffix(string(data), "\r\n--a b--\r\n") {
+		t.Errorf("请求体 = %q", data)
+	}
+}
@@ -0,0 +1,203 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// ParallelOptions 并行执行选项
+type ParallelOptions struct {
+	Selector    *Selector // 只执行选中的请求（及其前置请求），为nil时执行全部请求
+	Env         string    // 使用的环境
+	Concurrency int       // 最大并发数，小于1时按1处理
+	Repeat      int       // 每个请求的重复次数，小于1时按1处理；前置请求只执行一次
+}
+
+// parallelJob 表示一次请求执行
+type parallelJob struct {
+	request   *models.HTTPRequest
+	iteration int
+}
+
+// parallelResult 表示一次请求执行的结果
+type parallelResult struct {
+	job          parallelJob
+	resp         *models.HTTPResponse
+	dependencies []*models.HTTPResponse
+	err          error
+}
+
+// ExecuteParallel 使用固定数量的工作协程并发执行HTTP文件中的请求
+// 请求之间的依赖关系仍然有效：一个请求只有在它依赖的请求全部执行完成（包括所有重复次数）后才会开始执行，
+// 没有依赖关系的请求并发执行。返回的响应按执行顺序（请求顺序、重复次数）排列，
+// 启用--fail-fast时出现失败后不再开始新的请求，只返回已执行的请求的响应
+// 请求执行出错时不再开始新的请求，返回第一个错误和已执行的请求的响应，出错的请求记录为一个带有错误的响应
+func (e *Executor) ExecuteParallel(httpFile *models.HTTPFile, options ParallelOptions) ([]*models.HTTPResponse, *models.Statistics, error) {
+	concurrency := max(options.Concurrency, 1)
+	repeat := max(options.Repeat, 1)
+
+	targets, err := targetRequests(httpFile, options.Selector)
+	if err != nil {
+		return nil, nil, err
+	}
+	graph := buildDependencyGraph(httpFile, options.Env)
+	ordered, err := graph.order(targets)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := options.Selector.checkPrerequisites(ordered); err != nil {
+		return nil, nil, err
+	}
+
+	isTarget := make(map[*models.HTTPRequest]bool, len(targets))
+	for _, req := range targets {
+		isTarget[req] = true
+	}
+
+	// 统计每个请求需要执行的次数和尚未完成的依赖数
+	inPlan := make(map[*models.HTTPRequest]bool, len(ordered))
+	for _, req := range ordered {
+		inPlan[req] = true
+	}
+	iterations := make(map[*models.HTTPRequest]int, len(ordered))
+	remaining := make(map[*models.HTTPRequest]int, len(ordered))
+	pendingDeps := make(map[*models.HTTPRequest]int, len(ordered))
+	dependents := make(map[*models.HTTPRequest][]*models.HTTPRequest)
+	slots := make(map[*models.HTTPRequest][]*parallelResult, len(ordered))
+	for _, req := range ordered {
+		iterations[req] = 1
+		if isTarget[req] {
+			iterations[req] = repeat
+		}
+		if e.isExecuted(req) {
+			// 已经执行过的前置请求不再重复执行
+			iterations[req] = 0
+		}
+		remaining[req] = iterations[req]
+		slots[req] = make([]*parallelResult, iterations[req])
+		for _, dep := range graph.deps[req] {
+			if inPlan[dep] && !e.isExecuted(dep) {
+				pendingDeps[req]++
+				dependents[dep] = append(dependents[dep], req)
+			}
+		}
+	}
+
+	e.usePooledTransport(concurrency)
+
+	jobs := make(chan parallelJob)
+	results := make(chan *parallelResult)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if e.verbose {
+					fmt.Printf("\n===== 执行请求: %s (第 %d 次) =====\n", job.request.DisplayName(), job.iteration+1)
+				}
+				resp, dependencies, err := e.executeWithDependencies(httpFile, job.request, options.Env)
+				results <- &parallelResult{job: job, resp: resp, dependencies: dependencies, err: err}
+			}
+		}()
+	}
+
+	// 可以执行的任务队列，依赖全部完成的请求的所有重复次数都会加入队列
+	queue := make([]parallelJob, 0)
+	enqueue := func(req *models.HTTPRequest) {
+		for i := 0; i < iterations[req]; i++ {
+			queue = append(queue, parallelJob{request: req, iteration: i})
+		}
+	}
+	var complete func(req *models.HTTPRequest)
+	complete = func(req *models.HTTPRequest) {
+		for _, dependent := range dependents[req] {
+			pendingDeps[dependent]--
+			if pendingDeps[dependent] == 0 {
+				enqueue(dependent)
+				if iterations[dependent] == 0 {
+					complete(dependent)
+				}
+			}
+		}
+	}
+	for _, req := range ordered {
+		if pendingDeps[req] == 0 {
+			enqueue(req)
+			if iterations[req] == 0 {
+				complete(req)
+			}
+		}
+	}
+
+	start := time.Now()
+	inFlight := 0
+	var firstErr error
+	stopped := false
+	for len(queue) > 0 || inFlight > 0 {
+		// 出错或启用--fail-fast出现失败后不再分发新的任务，等待执行中的任务结束
+		var next chan parallelJob
+		var job parallelJob
+		if len(queue) > 0 && firstErr == nil && !stopped {
+			next = jobs
+			job = queue[0]
+		} else if inFlight == 0 {
+			break
+		}
+
+		select {
+		case next <- job:
+			queue = queue[1:]
+			inFlight++
+		case result := <-results:
+			inFlight--
+			req := result.job.request
+			slots[req][result.job.iteration] = result
+			if result.err != nil {
+				err := fmt.Errorf("执行请求 '%s' 失败: %w", req.DisplayName(), result.err)
+				result.resp = e.failedResponse(httpFile, req, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if e.shouldStop(append(result.dependencies, result.resp)...) {
+				stopped = true
+			}
+			remaining[req]--
+			if remaining[req] == 0 {
+				complete(req)
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	responses := make([]*models.HTTPResponse, 0, len(ordered)*repeat)
+	for _, req := range ordered {
+		for _, result := range slots[req] {
+			// 提前停止或出错时未执行的请求没有结果
+			if result == nil {
+				continue
+			}
+			responses = append(responses, result.dependencies...)
+			responses = append(responses, result.resp)
+		}
+	}
+	return responses, ComputeStatistics(responses, elapsed), firstErr
+}
+
+// usePooledTransport 默认Transport每个主机只保留2个空闲连接，并发执行时需要放大，避免频繁新建连接
+func (e *Executor) usePooledTransport(idleConns int) {
+	if e.client.Transport != nil {
+		return
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = idleConns
+	e.client.Transport = transport
+}
@@ -0,0 +1,245 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+	"github.com/shellus/jhttp/internal/parser"
+)
+
+// testServer 测试用的HTTP服务，记录每个路径收到的请求数
+//
+//	/login   返回{"token": "abc"}
+//	/user    Authorization为Bearer abc时返回200，否则返回401
+//	/fail    返回500
+//	其他路径 返回200和请求路径
+type testServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	count map[string]int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	s := &testServer{count: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.count[r.URL.Path]++
+		s.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"token": "abc"}`)
+		case "/user":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, "user")
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, r.URL.Path)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requests 返回路径收到的请求数
+func (s *testServer) requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count[path]
+}
+
+// parseTestFile 将内容中的{{host}}替换为服务地址后写入.http文件并解析
+func parseTestFile(t *testing.T, server *testServer, content string) *models.HTTPFile {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.http")
+	content = strings.ReplaceAll(content, "{{host}}", server.URL)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	httpFile, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() 错误 = %v", err)
+	}
+	return httpFile
+}
+
+// responseSummary 将响应概括为"请求名称 状态码"，便于比较
+func responseSummary(responses []*models.HTTPResponse) []string {
+	summary := make([]string, 0, len(responses))
+	for _, resp := range responses {
+		summary = append(summary, fmt.Sprintf("%s %d", resp.Request.Name, resp.StatusCode))
+	}
+	return summary
+}
+
+// 用户使用登录设置的token，位于登录之前
+const parallelTestFile = `### 用户
+GET {{host}}/user
+Authorization: Bearer {{token}}
+
+### 登录
+POST {{host}}/login
+
+> {% client.global.set("token", response.body.token); %}
+
+### 健康
+GET {{host}}/health
+`
+
+func TestExecuteParallel(t *testing.T) {
+	tests := []struct {
+		name      string
+		selector  []string
+		repeat    int
+		want      []string
+		wantLogin int
+	}{
+		{
+			name:      "全部请求",
+			repeat:    2,
+			want:      []string{"登录 200", "登录 200", "用户 200", "用户 200", "健康 200", "健康 200"},
+			wantLogin: 2,
+		},
+		{
+			name:      "前置请求只执行一次",
+			selector:  []string{"用户"},
+			repeat:    3,
+			want:      []string{"登录 200", "用户 200", "用户 200", "用户 200"},
+			wantLogin: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			httpFile := parseTestFile(t, server, parallelTestFile)
+			selector, err := ParseSelector(tt.selector, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := NewExecutor(false)
+			var callbacks atomic.Int32
+			e.SetResponseCallback(func(*models.HTTPFile, *models.HTTPResponse) { callbacks.Add(1) })
+			responses, stats, err := e.ExecuteParallel(httpFile, ParallelOptions{Selector: selector, Concurrency: 4, Repeat: tt.repeat})
+			if err != nil {
+				t.Fatalf("ExecuteParallel() 错误 = %v", err)
+			}
+			if got := responseSummary(responses); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("响应 = %v, 期望 %v", got, tt.want)
+			}
+			if server.requests("/login") != tt.wantLogin {
+				t.Errorf("登录请求数 = %d, 期望 %d", server.requests("/login"), tt.wantLogin)
+			}
+			if stats.Total != len(tt.want) || stats.Failed != 0 {
+				t.Errorf("统计 = %d/%d, 期望 %d/0", stats.Total, stats.Failed, len(tt.want))
+			}
+			if int(callbacks.Load()) != len(tt.want) {
+				t.Errorf("回调次数 = %d, 期望 %d", callbacks.Load(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestExecuteParallelFailFast(t *testing.T) {
+	server := newTestServer(t)
+	httpFile := parseTestFile(t, server, `### 失败
+GET {{host}}/fail
+
+### 之后
+GET {{host}}/after
+`)
+	patterns, err := ParseStatusPatterns("5xx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(false)
+	e.SetFailFast(true)
+	e.SetFailOnStatus(patterns)
+	responses, _, err := e.ExecuteParallel(httpFile, ParallelOptions{Concurrency: 1, Repeat: 3})
+	if err != nil {
+		t.Fatalf("ExecuteParallel() 错误 = %v", err)
+	}
+	// 只有一个工作协程，第一个失败的结果返回时最多已经分发了下一个任务
+	if len(responses) == 0 || len(responses) > 2 || responses[0].StatusCode != 500 {
+		t.Errorf("响应 = %v, 期望在第一个失败后停止", responseSummary(responses))
+	}
+	if server.requests("/after") != 0 {
+		t.Errorf("之后的请求执行了 %d 次, 期望不执行", server.requests("/after"))
+	}
+}
+
+func TestExecuteWithDependencies(t *testing.T) {
+	server := newTestServer(t)
+	content := `### A
+GET {{host}}/a
+
+### B
+GET {{host}}/b?a={{A.response.body.*}}
+
+### C
+GET {{host}}/c?b={{B.response.body.*}}
+`
+
+	// 多个协程共享执行器，按需执行的依赖请求的响应只返回给触发它们的调用
+	e := NewExecutor(false)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		httpFile := parseTestFile(t, server, content)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, dependencies, err := e.executeWithDependencies(httpFile, httpFile.FindRequestByName("C"), "")
+			if err != nil {
+				t.Errorf("executeWithDependencies() 错误 = %v", err)
+				return
+			}
+			if resp.Request.Name != "C" || resp.BodyString != "/c" {
+				t.Errorf("响应 = %s %q", resp.Request.Name, resp.BodyString)
+			}
+			if got := responseSummary(dependencies); !reflect.DeepEqual(got, []string{"A 200", "B 200"}) {
+				t.Errorf("依赖请求的响应 = %v, 期望 [A 200 B 200]", got)
+			}
+			for _, dep := range dependencies {
+				if named, _ := httpFile.NamedResponse(dep.Request.Name); named != dep {
+					t.Errorf("依赖请求 %s 的响应来自其他文件", dep.Request.Name)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestExecuteReferenceCycle(t *testing.T) {
+	server := newTestServer(t)
+	httpFile := parseTestFile(t, server, `### A
+GET {{host}}/a?b={{B.response.body.*}}
+
+### B
+GET {{host}}/b?a={{A.response.body.*}}
+`)
+
+	_, err := NewExecutor(false).Execute(httpFile, httpFile.FindRequestByName("A"), "")
+	if err == nil || !strings.Contains(err.Error(), "请求 'B' 与 'A' 之间存在循环引用") {
+		t.Errorf("Execute() 错误 = %v, 期望报告循环引用", err)
+	}
+	if server.requests("/a")+server.requests("/b") != 0 {
+		t.Error("存在循环引用时不应发送请求")
+	}
+}
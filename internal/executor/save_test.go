@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestSaveResponse(t *testing.T) {
+	dir := t.TempDir()
+	e := NewExecutor(false)
+	path := filepath.Join(dir, "out", "user.json")
+
+	// 文件不存在时使用原路径，目录自动创建；之后依次追加序号
+	for i, want := range []string{"user.json", "user-1.json", "user-2.json"} {
+		got, err := e.saveResponse(path, false, []byte(fmt.Sprint(i)))
+		if err != nil {
+			t.Fatalf("saveResponse() 错误 = %v", err)
+		}
+		if filepath.Base(got) != want {
+			t.Errorf("第%d次保存的文件 = %s, 期望 %s", i+1, filepath.Base(got), want)
+		}
+		if data, _ := os.ReadFile(got); string(data) != fmt.Sprint(i) {
+			t.Errorf("%s 的内容 = %q, 期望 %q", got, data, fmt.Sprint(i))
+		}
+	}
+
+	// 没有扩展名的文件
+	got, err := e.saveResponse(filepath.Join(dir, "out", "raw"), false, nil)
+	if err != nil || filepath.Base(got) != "raw" {
+		t.Errorf("saveResponse() = %s, %v, 期望 raw", got, err)
+	}
+	got, err = e.saveResponse(filepath.Join(dir, "out", "raw"), false, nil)
+	if err != nil || filepath.Base(got) != "raw-1" {
+		t.Errorf("saveResponse() = %s, %v, 期望 raw-1", got, err)
+	}
+}
+
+func TestSaveResponseExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "user.json")
+	// 其他进程已经创建了原文件和下一个序号的文件，O_EXCL创建失败后继续尝试下一个序号
+	for _, name := range []string{"user.json", "user-1.json", "user-3.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("旧内容"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := NewExecutor(false)
+	for _, want := range []string{"user-2.json", "user-4.json"} {
+		got, err := e.saveResponse(path, false, []byte("新内容"))
+		if err != nil {
+			t.Fatalf("saveResponse() 错误 = %v", err)
+		}
+		if filepath.Base(got) != want {
+			t.Errorf("保存的文件 = %s, 期望 %s", filepath.Base(got), want)
+		}
+	}
+	for _, name := range []string{"user.json", "user-1.json", "user-3.json"} {
+		if data, _ := os.ReadFile(filepath.Join(dir, name)); string(data) != "旧内容" {
+			t.Errorf("已存在的文件 %s 被覆盖: %q", name, data)
+		}
+	}
+
+	// 序号按执行器记录，新的执行器重新从原路径开始检查
+	got, err := NewExecutor(false).saveResponse(path, false, nil)
+	if err != nil || filepath.Base(got) != "user-5.json" {
+		t.Errorf("新执行器保存的文件 = %s, %v, 期望 user-5.json", got, err)
+	}
+}
+
+func TestSaveResponseConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "user.json")
+	e := NewExecutor(false)
+
+	const n = 20
+	paths := make([]string, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got, err := e.saveResponse(path, false, []byte(fmt.Sprint(i)))
+			if err != nil {
+				t.Errorf("saveResponse() 错误 = %v", err)
+			}
+			paths[i] = got
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i, p := range paths {
+		if seen[p] {
+			t.Errorf("多个请求保存到了同一个文件 %s", p)
+		}
+		seen[p] = true
+		if data, _ := os.ReadFile(p); string(data) != fmt.Sprint(i) {
+			t.Errorf("%s 的内容 = %q, 期望 %q", p, data, fmt.Sprint(i))
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != n {
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names)
+		t.Errorf("目录中的文件 = %v, 期望 %d 个", names, n)
+	}
+}
+
+func TestSaveResponseOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "user.json")
+	if err := os.WriteFile(path, []byte("很长的旧内容"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(false)
+	for _, body := range []string{"新", "再次覆盖"} {
+		got, err := e.saveResponse(path, true, []byte(body))
+		if err != nil || got != path {
+			t.Fatalf("saveResponse() = %s, %v, 期望 %s", got, err, path)
+		}
+		if data, _ := os.ReadFile(path); string(data) != body {
+			t.Errorf("覆盖后的内容 = %q, 期望 %q", data, body)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("文件权限 = %v, 期望 0644", info.Mode().Perm())
+	}
+	// 不会留下临时文件
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("目录中有 %d 个文件, 期望 1 个", len(entries))
+	}
+}
@@ -0,0 +1,376 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// Selector 选择要执行的请求，支持以下写法，多个选择可以重复指定或用逗号分隔：
+//
+//	获取用户信息      请求名称，有多个同名请求时全部选中
+//	3                 --list中显示的序号（从1开始）
+//	user.http:42      文件中第42行的请求（请求行所在的行号）
+//	/^获取.*图片$/    名称匹配正则表达式
+//	获取*             名称匹配通配符，*匹配任意字符，?匹配一个字符；有同名请求时按名称选择
+//
+// 序号只能在执行单个文件时使用，执行多个文件时使用"文件:行号"选择。
+// 还可以按标签筛选：指定了标签时只选择至少有其中一个标签的请求，有排除标签的请求不会被选中。
+// 选中的请求按文件中的顺序执行
+type Selector struct {
+	groups      []selectorGroup
+	tags        []string // 请求至少要有其中一个标签
+	excludeTags []string // 有其中任意一个标签的请求不选择
+}
+
+// selectorGroup 表示一个--request参数
+// 参数中包含逗号时，先按完整名称匹配，没有同名请求时再按逗号分隔后的各项匹配，/正则表达式/中的逗号不分隔
+type selectorGroup struct {
+	spec  string
+	items []selectorItem
+}
+
+// selectorItem 表示一项选择
+type selectorItem struct {
+	spec    string
+	name    string         // 请求名称，通配符也保留原文，有同名请求时按名称匹配
+	index   int            // 序号，从1开始
+	file    string         // 行号所属的文件
+	line    int            // 行号
+	pattern *regexp.Regexp // 名称的正则表达式或通配符
+}
+
+// fileLineRegex 匹配"文件:行号"，文件扩展名为.http或.rest
+var fileLineRegex = regexp.MustCompile(`(?i)^(.+\.(?:http|rest)):(\d+)$`)
+
+// ParseSelector 解析--request、--tags和--exclude-tags参数，标签可以用逗号分隔
+// 没有任何参数时返回nil，表示执行全部请求
+func ParseSelector(specs, tags, excludeTags []string) (*Selector, error) {
+	s := &Selector{tags: splitList(tags), excludeTags: splitList(excludeTags)}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		group := selectorGroup{spec: spec}
+		for _, part := range splitSpec(spec) {
+			item, err := parseSelectorItem(part)
+			if err != nil {
+				return nil, err
+			}
+			group.items = append(group.items, item)
+		}
+		s.groups = append(s.groups, group)
+	}
+
+	if len(s.groups) == 0 && len(s.tags) == 0 && len(s.excludeTags) == 0 {
+		return nil, nil
+	}
+	return s, nil
+}
+
+// splitSpec 按逗号拆分--request参数，以/开头的正则表达式在遇到结束的/之前不拆分，如"/a{1,3}/,登录"
+func splitSpec(spec string) []string {
+	parts := make([]string, 0)
+	var current strings.Builder
+	flush := func() {
+		if part := strings.TrimSpace(current.String()); part != "" {
+			parts = append(parts, part)
+		}
+		current.Reset()
+	}
+
+	for _, r := range spec {
+		if r == ',' {
+			part := strings.TrimSpace(current.String())
+			inRegexp := strings.HasPrefix(part, "/") && (len(part) < 2 || !strings.HasSuffix(part, "/"))
+			if !inRegexp {
+				flush()
+				continue
+			}
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return parts
+}
+
+// splitList 拆分逗号分隔的参数
+func splitList(values []string) []string {
+	items := make([]string, 0)
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// parseSelectorItem 解析一项选择
+func parseSelectorItem(spec string) (selectorItem, error) {
+	item := selectorItem{spec: spec}
+
+	switch {
+	case len(spec) > 2 && strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/"):
+		pattern, err := regexp.Compile(spec[1 : len(spec)-1])
+		if err != nil {
+			return item, fmt.Errorf("无效的正则表达式 '%s': %w", spec, err)
+		}
+		item.pattern = pattern
+
+	case isDigits(spec):
+		index, err := strconv.Atoi(spec)
+		if err != nil || index < 1 {
+			return item, fmt.Errorf("无效的请求序号 '%s'，序号从1开始", spec)
+		}
+		item.index = index
+
+	case fileLineRegex.MatchString(spec):
+		matches := fileLineRegex.FindStringSubmatch(spec)
+		item.file = matches[1]
+		item.line, _ = strconv.Atoi(matches[2])
+
+	case strings.ContainsAny(spec, "*?["):
+		pattern, err := nameGlobRegexp(spec)
+		if err != nil {
+			return item, fmt.Errorf("无效的通配符 '%s': %w", spec, err)
+		}
+		item.pattern = pattern
+		item.name = spec
+
+	default:
+		item.name = spec
+	}
+	return item, nil
+}
+
+// isDigits 判断字符串是否只包含数字
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// nameGlobRegexp 将请求名称的通配符转换为正则表达式，*匹配任意字符（包括/），?匹配一个字符
+func nameGlobRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("缺少 ']'")
+			}
+			class := string(runes[i+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// match 判断文件中的第index个请求（从1开始）是否被选中
+func (item selectorItem) match(httpFile *models.HTTPFile, index int, req *models.HTTPRequest) bool {
+	switch {
+	case item.pattern != nil && item.name != "" && httpFile.FindRequestByName(item.name) != nil:
+		// 名称中包含*、?或[的请求，优先按完整名称匹配
+		return req.Name == item.name
+	case item.pattern != nil:
+		return item.pattern.MatchString(req.DisplayName())
+	case item.index > 0:
+		return item.index == index
+	case item.file != "":
+		return item.line == req.LineNumber && sameFile(httpFile.Path, item.file)
+	}
+	return req.Name == item.name
+}
+
+// sameFile 判断文件路径是否与选择中的文件相同，选择中的文件可以只写文件名或路径的末尾部分
+func sameFile(path, file string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	file = filepath.ToSlash(filepath.Clean(file))
+	if path == file || strings.HasSuffix(path, "/"+file) {
+		return true
+	}
+	if absPath, err := filepath.Abs(path); err == nil {
+		if absFile, err := filepath.Abs(file); err == nil && absPath == absFile {
+			return true
+		}
+	}
+	return false
+}
+
+// HasIndex 判断是否有按序号的选择
+func (s *Selector) HasIndex() bool {
+	if s == nil {
+		return false
+	}
+	for _, group := range s.groups {
+		for _, item := range group.items {
+			if item.index > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String 返回选择的原始写法
+func (s *Selector) String() string {
+	specs := make([]string, 0, len(s.groups)+2)
+	for _, group := range s.groups {
+		specs = append(specs, group.spec)
+	}
+	if len(s.tags) > 0 {
+		specs = append(specs, "标签 "+strings.Join(s.tags, ","))
+	}
+	if len(s.excludeTags) > 0 {
+		specs = append(specs, "排除标签 "+strings.Join(s.excludeTags, ","))
+	}
+	return strings.Join(specs, ", ")
+}
+
+// Select 返回文件中被选中的请求，按文件中的顺序排列；Selector为nil时返回全部请求
+func (s *Selector) Select(httpFile *models.HTTPFile) []*models.HTTPRequest {
+	if s == nil {
+		return httpFile.Requests
+	}
+
+	selected := make([]*models.HTTPRequest, 0)
+	for i, req := range httpFile.Requests {
+		if s.matchTags(req) && s.matchGroups(httpFile, i+1, req) {
+			selected = append(selected, req)
+		}
+	}
+	return selected
+}
+
+// matchGroups 判断请求是否被任意一个--request参数选中，没有--request参数时选择全部请求
+func (s *Selector) matchGroups(httpFile *models.HTTPFile, index int, req *models.HTTPRequest) bool {
+	if len(s.groups) == 0 {
+		return true
+	}
+	for _, group := range s.groups {
+		if group.match(httpFile, index, req) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTags 判断请求的标签是否符合--tags和--exclude-tags
+func (s *Selector) matchTags(req *models.HTTPRequest) bool {
+	for _, tag := range s.excludeTags {
+		if req.HasTag(tag) {
+			return false
+		}
+	}
+	if len(s.tags) == 0 {
+		return true
+	}
+	for _, tag := range s.tags {
+		if req.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPrerequisites 检查按依赖关系加入的前置请求是否带有--exclude-tags中的标签
+// 选中的请求已经排除了这些标签，ordered中带有排除标签的请求只能是前置请求；执行它们违背排除的意图，因此报错
+func (s *Selector) checkPrerequisites(ordered []*models.HTTPRequest) error {
+	if s == nil {
+		return nil
+	}
+	for _, req := range ordered {
+		for _, tag := range s.excludeTags {
+			if req.HasTag(tag) {
+				return fmt.Errorf("前置请求 '%s' 带有排除的标签 '%s'", req.DisplayName(), tag)
+			}
+		}
+	}
+	return nil
+}
+
+// match 判断请求是否被这个参数选中
+func (g selectorGroup) match(httpFile *models.HTTPFile, index int, req *models.HTTPRequest) bool {
+	if g.hasWholeName(httpFile) {
+		return req.Name == g.spec
+	}
+	for _, item := range g.items {
+		if item.match(httpFile, index, req) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasWholeName 判断包含逗号的参数是否是文件中某个请求的完整名称
+func (g selectorGroup) hasWholeName(httpFile *models.HTTPFile) bool {
+	return len(g.items) > 1 && httpFile.FindRequestByName(g.spec) != nil
+}
+
+// Unmatched 返回在所有文件中都没有选中任何请求的--request选择，不考虑标签
+func (s *Selector) Unmatched(httpFiles ...*models.HTTPFile) []string {
+	if s == nil {
+		return nil
+	}
+
+	unmatched := make([]string, 0)
+	for _, group := range s.groups {
+		wholeName := false
+		for _, httpFile := range httpFiles {
+			if group.hasWholeName(httpFile) {
+				wholeName = true
+				break
+			}
+		}
+		if wholeName {
+			continue
+		}
+
+		for _, item := range group.items {
+			if !item.matchAny(httpFiles) {
+				unmatched = append(unmatched, item.spec)
+			}
+		}
+	}
+	return unmatched
+}
+
+// matchAny 判断是否选中了任意文件中的任意请求
+func (item selectorItem) matchAny(httpFiles []*models.HTTPFile) bool {
+	for _, httpFile := range httpFiles {
+		for i, req := range httpFile.Requests {
+			if item.match(httpFile, i+1, req) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// newSelectorFile 创建用于测试选择的文件，请求的行号依次为10、20、30……
+func newSelectorFile(path string, names ...string) *models.HTTPFile {
+	file := models.NewHTTPFile(path)
+	for i, name := range names {
+		req := &models.HTTPRequest{Name: name, Method: "GET", LineNumber: (i + 1) * 10}
+		switch name {
+		case "登录":
+			req.AddTag("auth")
+			req.AddTag("smoke")
+		case "获取用户信息":
+			req.AddTag("smoke")
+		case "上传图片":
+			req.AddTag("slow")
+		}
+		file.AddRequest(req)
+	}
+	return file
+}
+
+// selectedNames 返回选中的请求名称
+func selectedNames(requests []*models.HTTPRequest) []string {
+	names := make([]string, 0, len(requests))
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	return names
+}
+
+func TestSelectorSelect(t *testing.T) {
+	file := newSelectorFile("api/user.http",
+		"登录", "获取用户信息", "获取用户[v2]", "上传图片", "a,b", "aa", "aaaa", "登录")
+
+	tests := []struct {
+		name        string
+		specs       []string
+		tags        []string
+		excludeTags []string
+		want        []string
+	}{
+		{"名称选中所有同名请求", []string{"登录"}, nil, nil, []string{"登录", "登录"}},
+		{"序号", []string{"2"}, nil, nil, []string{"获取用户信息"}},
+		{"文件和行号", []string{"user.http:40"}, nil, nil, []string{"上传图片"}},
+		{"带目录的文件和行号", []string{"api/user.http:20"}, nil, nil, []string{"获取用户信息"}},
+		{"其他文件的行号", []string{"other.http:20"}, nil, nil, []string{}},
+		{"正则表达式", []string{"/^获取/"}, nil, nil, []string{"获取用户信息", "获取用户[v2]"}},
+		{"正则表达式中的逗号", []string{"/^a{1,3}$/"}, nil, nil, []string{"aa"}},
+		{"正则表达式和名称", []string{"/^a{2}$/,上传图片"}, nil, nil, []string{"上传图片", "aa"}},
+		{"通配符", []string{"获取*"}, nil, nil, []string{"获取用户信息", "获取用户[v2]"}},
+		{"包含[的完整名称优先", []string{"获取用户[v2]"}, nil, nil, []string{"获取用户[v2]"}},
+		{"逗号分隔按文件顺序执行", []string{"上传图片,2"}, nil, nil, []string{"获取用户信息", "上传图片"}},
+		{"包含逗号的完整名称", []string{"a,b"}, nil, nil, []string{"a,b"}},
+		{"重复指定", []string{"aa", "aaaa"}, nil, nil, []string{"aa", "aaaa"}},
+		{"标签", nil, []string{"smoke"}, nil, []string{"登录", "获取用户信息", "登录"}},
+		{"逗号分隔的标签", nil, []string{"auth,slow"}, nil, []string{"登录", "上传图片", "登录"}},
+		{"排除标签", []string{"/^(登录|获取用户信息|上传图片)$/"}, nil, []string{"auth"}, []string{"获取用户信息", "上传图片"}},
+		{"名称和标签同时满足", []string{"/^获取/"}, []string{"smoke"}, nil, []string{"获取用户信息"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.specs, tt.tags, tt.excludeTags)
+			if err != nil {
+				t.Fatalf("ParseSelector() 错误 = %v", err)
+			}
+			if got := selectedNames(selector.Select(file)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		specs     []string
+		wantNil   bool
+		wantIndex bool
+		wantErr   string
+	}{
+		{"没有参数", nil, true, false, ""},
+		{"只有空白", []string{" ", ""}, true, false, ""},
+		{"名称", []string{"登录"}, false, false, ""},
+		{"序号", []string{"登录,3"}, false, true, ""},
+		{"序号从1开始", []string{"0"}, false, false, "序号从1开始"},
+		{"无效的正则表达式", []string{"/(/"}, false, false, "无效的正则表达式"},
+		{"无效的通配符", []string{"获取[用户"}, false, false, "无效的通配符"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.specs, nil, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseSelector() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSelector() 错误 = %v", err)
+			}
+			if (selector == nil) != tt.wantNil {
+				t.Errorf("ParseSelector() = %v, 期望nil = %v", selector, tt.wantNil)
+			}
+			if got := selector.HasIndex(); got != tt.wantIndex {
+				t.Errorf("HasIndex() = %v, 期望 %v", got, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestSelectorNil(t *testing.T) {
+	var selector *Selector
+	file := newSelectorFile("user.http", "登录", "上传图片")
+	if got := selector.Select(file); len(got) != 2 {
+		t.Errorf("Select() 返回 %d 个请求, 期望全部 2 个", len(got))
+	}
+	if got := selector.Unmatched(file); len(got) != 0 {
+		t.Errorf("Unmatched() = %v", got)
+	}
+}
+
+func TestSelectorUnmatched(t *testing.T) {
+	user := newSelectorFile("user.http", "登录", "获取用户信息", "a,b")
+	order := newSelectorFile("order.http", "下单")
+
+	tests := []struct {
+		name  string
+		specs []string
+		want  []string
+	}{
+		{"全部匹配", []string{"登录", "order.http:10"}, []string{}},
+		{"不存在的名称", []string{"登录,注销"}, []string{"注销"}},
+		{"其他文件中匹配", []string{"下单"}, []string{}},
+		{"不存在的行号", []string{"user.http:15"}, []string{"user.http:15"}},
+		{"没有匹配的正则表达式", []string{"/^删除/"}, []string{"/^删除/"}},
+		{"包含逗号的完整名称", []string{"a,b"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.specs, nil, nil)
+			if err != nil {
+				t.Fatalf("ParseSelector() 错误 = %v", err)
+			}
+			if got := selector.Unmatched(user, order); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmatched() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"登录", []string{"登录"}},
+		{"登录, 3 ,", []string{"登录", "3"}},
+		{"/a{1,3}/", []string{"/a{1,3}/"}},
+		{"/a{1,3}/,登录,/b{2,}/", []string{"/a{1,3}/", "登录", "/b{2,}/"}},
+		{"/,", []string{"/,"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitSpec(tt.spec); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitSpec(%q) = %v, 期望 %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestSelectorExcludedPrerequisite(t *testing.T) {
+	// 用户依赖登录设置的token，登录带有slow标签
+	content := `### 登录
+# @tag slow
+POST {{host}}/login
+
+> {% client.global.set("token", response.body.token); %}
+
+### 用户
+GET {{host}}/user
+Authorization: Bearer {{token}}
+
+### 健康
+# @tag slow
+GET {{host}}/health
+`
+	const wantErr = "前置请求 '登录' 带有排除的标签 'slow'"
+
+	run := map[string]func(e *Executor, httpFile *models.HTTPFile, selector *Selector) error{
+		"ExecuteFile": func(e *Executor, httpFile *models.HTTPFile, selector *Selector) error {
+			_, err := e.ExecuteFile(httpFile, selector, "")
+			return err
+		},
+		"ExecuteParallel": func(e *Executor, httpFile *models.HTTPFile, selector *Selector) error {
+			_, _, err := e.ExecuteParallel(httpFile, ParallelOptions{Selector: selector, Concurrency: 2})
+			return err
+		},
+		"ExecuteLoad": func(e *Executor, httpFile *models.HTTPFile, selector *Selector) error {
+			_, err := e.ExecuteLoad(httpFile, LoadOptions{Selector: selector, Stages: []LoadStage{{10 * time.Millisecond, 100}}})
+			return err
+		},
+	}
+
+	for name, execute := range run {
+		t.Run(name, func(t *testing.T) {
+			server := newTestServer(t)
+			httpFile := parseTestFile(t, server, content)
+			selector, err := ParseSelector(nil, nil, []string{"slow"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = execute(NewExecutor(false), httpFile, selector)
+			if err == nil || !strings.Contains(err.Error(), wantErr) {
+				t.Fatalf("%s() 错误 = %v, 期望包含 %q", name, err, wantErr)
+			}
+			if server.requests("/login")+server.requests("/user")+server.requests("/health") != 0 {
+				t.Error("前置请求被排除时不应发送任何请求")
+			}
+		})
+	}
+
+	// 排除的请求不是前置请求时正常执行
+	server := newTestServer(t)
+	httpFile := parseTestFile(t, server, content)
+	selector, err := ParseSelector([]string{"健康"}, nil, []string{"auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses, err := NewExecutor(false).ExecuteFile(httpFile, selector, "")
+	if err != nil || !reflect.DeepEqual(responseSummary(responses), []string{"健康 200"}) {
+		t.Errorf("ExecuteFile() = %v, %v", responseSummary(responses), err)
+	}
+}
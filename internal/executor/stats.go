@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// ComputeStatistics 根据响应计算统计信息，elapsed为执行这些请求的总耗时
+// 除汇总统计外，还按请求分组统计，分组顺序与请求首次出现的顺序一致
+func ComputeStatistics(responses []*models.HTTPResponse, elapsed time.Duration) *models.Statistics {
+	stats := computeGroup("", responses, elapsed)
+
+	// 执行时的请求是变量替换后的副本，按请求所在行号归并同一个请求的多次执行
+	groups := make(map[int][]*models.HTTPResponse)
+	order := make([]int, 0)
+	for _, resp := range responses {
+		line := resp.Request.LineNumber
+		if _, ok := groups[line]; !ok {
+			order = append(order, line)
+		}
+		groups[line] = append(groups[line], resp)
+	}
+	for _, line := range order {
+		group := groups[line]
+		stats.Requests = append(stats.Requests, computeGroup(group[0].Request.DisplayName(), group, elapsed))
+	}
+	return stats
+}
+
+// MergeStatistics 合并多个文件的统计信息，汇总统计根据全部响应重新计算，按请求的统计按文件顺序拼接
+// 不同文件中的请求行号可能相同，因此不能直接对全部响应调用ComputeStatistics
+func MergeStatistics(parts []*models.Statistics, responses []*models.HTTPResponse, elapsed time.Duration) *models.Statistics {
+	stats := computeGroup("", responses, elapsed)
+	for _, part := range parts {
+		stats.Requests = append(stats.Requests, part.Requests...)
+	}
+	return stats
+}
+
+// computeGroup 计算一组响应的统计信息
+func computeGroup(name string, responses []*models.HTTPResponse, elapsed time.Duration) *models.Statistics {
+	stats := &models.Statistics{Name: name, Total: len(responses), Elapsed: elapsed}
+	if len(responses) == 0 {
+		return stats
+	}
+
+	times := make([]int64, 0, len(responses))
+	var sum int64
+	for _, resp := range responses {
+		if resp.Error == nil && resp.StatusCode < 400 {
+			stats.Succeeded++
+		} else {
+			stats.Failed++
+		}
+		times = append(times, resp.Time)
+		sum += resp.Time
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	stats.MinTime = times[0]
+	stats.MaxTime = times[len(times)-1]
+	stats.AvgTime = float64(sum) / float64(len(times))
+	stats.P50Time = percentile(times, 50)
+	stats.P90Time = percentile(times, 90)
+	stats.P99Time = percentile(times, 99)
+	return stats
+}
+
+// percentile 使用最近秩法计算已排序数据的百分位数
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// PrintStatistics 打印统计信息，包含多个请求时同时打印每个请求的统计
+func PrintStatistics(stats *models.Statistics) {
+	fmt.Println("\n统计信息:")
+	fmt.Printf("  请求总数: %d，成功: %d，失败: %d，成功率: %.2f%%\n",
+		stats.Total, stats.Succeeded, stats.Failed, stats.SuccessRate())
+	fmt.Printf("  总耗时: %d ms，吞吐量: %.2f 请求/秒\n", stats.Elapsed.Milliseconds(), stats.Throughput())
+	printLatency("  ", stats)
+
+	if len(stats.Requests) > 1 {
+		fmt.Println("\n  按请求统计:")
+		for _, s := range stats.Requests {
+			fmt.Printf("  %s: %d 次，成功率 %.2f%%\n", s.Name, s.Total, s.SuccessRate())
+			printLatency("    ", s)
+		}
+	}
+}
+
+// printLatency 打印响应时间分布
+func printLatency(indent string, stats *models.Statistics) {
+	fmt.Printf("%s响应时间: 最小 %d ms，平均 %.1f ms，p50 %d ms，p90 %d ms，p99 %d ms，最大 %d ms\n",
+		indent, stats.MinTime, stats.AvgTime, stats.P50Time, stats.P90Time, stats.P99Time, stats.MaxTime)
+}
@@ -0,0 +1,27 @@
+package executor
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		sorted []int64
+		p      float64
+		want   int64
+	}{
+		{[]int64{7}, 50, 7},
+		{[]int64{7}, 99, 7},
+		{[]int64{1, 2, 3, 4}, 0, 1},
+		{[]int64{1, 2, 3, 4}, 25, 1},
+		{[]int64{1, 2, 3, 4}, 50, 2},
+		{[]int64{1, 2, 3, 4}, 51, 3},
+		{[]int64{1, 2, 3, 4}, 100, 4},
+		{[]int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, 90, 90},
+		{[]int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, 95, 100},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v, %v) = %d, 期望 %d", tt.sorted, tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// timingTracer 通过httptrace记录请求各阶段的耗时
+// 建立连接时可能同时尝试多个地址，回调会在不同协程中执行，因此需要加锁
+type timingTracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	timing       models.Timing
+}
+
+// newTimingTracer 创建从start开始计时的记录器
+func newTimingTracer(start time.Time) *timingTracer {
+	return &timingTracer{start: start}
+}
+
+// trace 返回记录耗时的httptrace回调
+func (t *timingTracer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.DNSLookup += time.Since(t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && !t.connectStart.IsZero() {
+				t.timing.Connect += time.Since(t.connectStart)
+				t.connectStart = time.Time{}
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TLSHandshake += time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.ConnectionReused = info.Reused
+			t.timing.IdleTime = info.IdleTime
+			if info.Conn != nil {
+				t.timing.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wroteRequest = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+		},
+	}
+}
+
+// finish 在读取完响应体（或请求失败）时结束计时，返回各阶段耗时
+func (t *timingTracer) finish(end time.Time) *models.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timing := t.timing
+	timing.Total = end.Sub(t.start)
+	if !t.firstByte.IsZero() {
+		timing.TimeToFirstByte = t.firstByte.Sub(t.start)
+		timing.ContentTransfer = end.Sub(t.firstByte)
+		if !t.wroteRequest.IsZero() {
+			timing.ServerProcessing = t.firstByte.Sub(t.wroteRequest)
+		}
+	}
+	return &timing
+}
+
+// printTiming 以类似curl -w的格式打印各阶段耗时，右侧为从开始请求算起的累计时间
+func printTiming(timing *models.Timing) {
+	connected := timing.DNSLookup + timing.Connect
+	handshaked := connected + timing.TLSHandshake
+
+	fmt.Println("\n耗时分析:")
+	fmt.Printf("  DNS解析:      %8s  (累计 %s)\n", formatLatency(timing.DNSLookup), formatLatency(timing.DNSLookup))
+	fmt.Printf("  TCP连接:      %8s  (累计 %s)\n", formatLatency(timing.Connect), formatLatency(connected))
+	fmt.Printf("  TLS握手:      %8s  (累计 %s)\n", formatLatency(timing.TLSHandshake), formatLatency(handshaked))
+	fmt.Printf("  服务器处理:   %8s  (首字节 %s)\n", formatLatency(timing.ServerProcessing), formatLatency(timing.TimeToFirstByte))
+	fmt.Printf("  内容传输:     %8s  (累计 %s)\n", formatLatency(timing.ContentTransfer), formatLatency(timing.Total))
+	fmt.Printf("  总耗时:       %8s\n", formatLatency(timing.Total))
+
+	reused := "否"
+	if timing.ConnectionReused {
+		reused = fmt.Sprintf("是（空闲 %s）", formatLatency(timing.IdleTime))
+	}
+	fmt.Printf("  连接复用:     %s\n", reused)
+	if timing.RemoteAddr != "" {
+		fmt.Printf("  服务器地址:   %s\n", timing.RemoteAddr)
+	}
+}
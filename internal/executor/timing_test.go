@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestTimingTracerFinish(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ms := func(n int) time.Time { return start.Add(time.Duration(n) * time.Millisecond) }
+
+	tests := []struct {
+		name         string
+		wroteRequest time.Time
+		firstByte    time.Time
+		end          time.Time
+		want         models.Timing
+	}{
+		{
+			name:         "完整的请求",
+			wroteRequest: ms(10),
+			firstByte:    ms(40),
+			end:          ms(55),
+			want:         models.Timing{ServerProcessing: 30 * time.Millisecond, TimeToFirstByte: 40 * time.Millisecond, ContentTransfer: 15 * time.Millisecond, Total: 55 * time.Millisecond},
+		},
+		{
+			name:         "发送请求后失败",
+			wroteRequest: ms(10),
+			end:          ms(30),
+			want:         models.Timing{Total: 30 * time.Millisecond},
+		},
+		{
+			name: "连接失败",
+			end:  ms(5),
+			want: models.Timing{Total: 5 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := newTimingTracer(start)
+			tracer.wroteRequest = tt.wroteRequest
+			tracer.firstByte = tt.firstByte
+			if got := tracer.finish(tt.end); *got != tt.want {
+				t.Errorf("finish() = %+v, 期望 %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimingTracerConnectAttempts(t *testing.T) {
+	tracer := newTimingTracer(time.Now())
+	trace := tracer.trace()
+
+	// 同时尝试IPv6和IPv4地址，失败的尝试不计入，只记录从第一次开始到成功建立连接的时间
+	trace.ConnectStart("tcp", "[::1]:80")
+	trace.ConnectStart("tcp", "127.0.0.1:80")
+	time.Sleep(5 * time.Millisecond)
+	trace.ConnectDone("tcp", "[::1]:80", errors.New("connection refused"))
+	trace.ConnectDone("tcp", "127.0.0.1:80", nil)
+
+	timing := tracer.finish(time.Now())
+	if timing.Connect < 5*time.Millisecond {
+		t.Errorf("Connect = %v, 期望至少 5ms", timing.Connect)
+	}
+	if timing.Connect > timing.Total {
+		t.Errorf("Connect = %v, 超过总耗时 %v", timing.Connect, timing.Total)
+	}
+}
+
+func TestTimingTrace(t *testing.T) {
+	// 服务端处理30ms后返回响应头，再过20ms返回剩余的响应体
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		fmt.Fprint(w, "first")
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, "second")
+	})
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		tls    bool
+	}{
+		{name: "HTTP", server: httptest.NewServer(handler)},
+		{name: "HTTPS", server: httptest.NewTLSServer(handler), tls: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer tt.server.Close()
+			e := NewExecutor(false)
+			e.client.Transport = tt.server.Client().Transport
+
+			httpFile := models.NewHTTPFile("test.http")
+			req := &models.HTTPRequest{Method: "GET", Headers: make(http.Header)}
+			req.URL, _ = url.Parse(tt.server.URL + "/")
+
+			for i, reused := range []bool{false, true} {
+				resp, err := e.execute(httpFile, req, "")
+				if err != nil {
+					t.Fatalf("execute() 错误 = %v", err)
+				}
+				if resp.Error != nil {
+					t.Fatalf("请求失败: %v", resp.Error)
+				}
+				timing := resp.Timing
+				if timing.ConnectionReused != reused {
+					t.Errorf("第%d次请求 ConnectionReused = %v, 期望 %v", i+1, timing.ConnectionReused, reused)
+				}
+				if timing.RemoteAddr != tt.server.Listener.Addr().String() {
+					t.Errorf("RemoteAddr = %q, 期望 %q", timing.RemoteAddr, tt.server.Listener.Addr())
+				}
+				if reused {
+					if timing.Connect != 0 || timing.TLSHandshake != 0 {
+						t.Errorf("复用连接时 Connect = %v, TLSHandshake = %v, 期望 0", timing.Connect, timing.TLSHandshake)
+					}
+				} else {
+					if timing.Connect <= 0 {
+						t.Errorf("Connect = %v, 期望大于0", timing.Connect)
+					}
+					if (timing.TLSHandshake > 0) != tt.tls {
+						t.Errorf("TLSHandshake = %v", timing.TLSHandshake)
+					}
+				}
+				if timing.ServerProcessing < 30*time.Millisecond || timing.ContentTransfer < 20*time.Millisecond {
+					t.Errorf("ServerProcessing = %v, ContentTransfer = %v, 期望至少 30ms/20ms", timing.ServerProcessing, timing.ContentTransfer)
+				}
+				if timing.TimeToFirstByte < timing.ServerProcessing || timing.Total < timing.TimeToFirstByte+timing.ContentTransfer {
+					t.Errorf("各阶段耗时不一致: %+v", timing)
+				}
+			}
+		})
+	}
+}
+
+func TestTimingTraceFailure(t *testing.T) {
+	// 连接失败时也返回已记录的耗时
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	req := &models.HTTPRequest{Method: "GET", Headers: make(http.Header)}
+	req.URL, _ = url.Parse(server.URL + "/")
+	resp, err := NewExecutor(false).execute(models.NewHTTPFile("test.http"), req, "")
+	if err != nil {
+		t.Fatalf("execute() 错误 = %v", err)
+	}
+	if resp.Error == nil {
+		t.Fatal("期望连接失败")
+	}
+	if resp.Timing == nil || resp.Timing.Total <= 0 || resp.Timing.TimeToFirstByte != 0 || resp.Timing.ServerProcessing != 0 {
+		t.Errorf("Timing = %+v", resp.Timing)
+	}
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"mime"
+	"os"
+	"strings"
+)
+
+// ANSI颜色
+const (
+	colorReset     = "\x1b[0m"
+	colorKey       = "\x1b[1;34m" // JSON键
+	colorString    = "\x1b[32m"   // 字符串、属性值
+	colorNumber    = "\x1b[33m"   // 数字
+	colorLiteral   = "\x1b[35m"   // true、false、null
+	colorTag       = "\x1b[34m"   // XML/HTML标签
+	colorAttribute = "\x1b[36m"   // XML/HTML属性名
+	colorComment   = "\x1b[90m"   // 注释、声明
+)
+
+// Options 输出格式选项
+type Options struct {
+	Pretty bool // 格式化（缩进）响应体
+	Color  bool // 彩色输出
+}
+
+// Body 根据Content-Type格式化响应体，JSON和XML会缩进和着色，HTML只着色；
+// 其他类型或内容无法解析时原样返回
+func Body(contentType string, body string, opts Options) string {
+	if !opts.Pretty && !opts.Color {
+		return body
+	}
+
+	switch mediaKind(contentType) {
+	case "json":
+		return FormatJSON(body, opts.Pretty, opts.Color)
+	case "xml":
+		return FormatXML(body, opts.Pretty, opts.Color)
+	case "html":
+		return FormatHTML(body, opts.Color)
+	}
+	return body
+}
+
+// mediaKind 返回Content-Type对应的格式：json、xml、html，无法识别时为空
+func mediaKind(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return "json"
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return "xml"
+	case mediaType == "text/html":
+		return "html"
+	}
+	return ""
+}
+
+// ColorSupported 判断标准输出是否适合彩色输出：设置了NO_COLOR环境变量或输出不是终端时不使用颜色
+func ColorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint 为文本添加颜色
+func paint(b *strings.Builder, color, text string) {
+	b.WriteString(color)
+	b.WriteString(text)
+	b.WriteString(colorReset)
+}
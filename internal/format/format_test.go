@@ -0,0 +1,182 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+// marked 将颜色代码替换为便于阅读的标记，如[key]、[/]
+func marked(s string) string {
+	return strings.NewReplacer(
+		colorReset, "[/]",
+		colorKey, "[key]",
+		colorString, "[str]",
+		colorNumber, "[num]",
+		colorLiteral, "[lit]",
+		colorTag, "[tag]",
+		colorAttribute, "[attr]",
+		colorComment, "[cmt]",
+	).Replace(s)
+}
+
+func TestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		opts        Options
+		want        string
+	}{
+		{"不格式化", "application/json", `{"a":1}`, Options{}, `{"a":1}`},
+		{"JSON", "application/json; charset=utf-8", `{"a":1}`, Options{Pretty: true}, "{\n  \"a\": 1\n}"},
+		{"+json", "application/problem+json", `{"a":1}`, Options{Pretty: true}, "{\n  \"a\": 1\n}"},
+		{"大小写和空白", " Application/JSON ", `{"a":1}`, Options{Pretty: true}, "{\n  \"a\": 1\n}"},
+		{"XML", "text/xml", `<a><b>1</b></a>`, Options{Pretty: true}, "<a>\n  <b>1</b>\n</a>"},
+		{"+xml", "application/soap+xml", `<a><b>1</b></a>`, Options{Pretty: true}, "<a>\n  <b>1</b>\n</a>"},
+		{"HTML只着色", "text/html", `<p>x</p>`, Options{Pretty: true}, `<p>x</p>`},
+		{"HTML着色", "text/html", `<p>x</p>`, Options{Color: true}, "[tag]<p[/][tag]>[/]x[tag]</p[/][tag]>[/]"},
+		{"其他类型", "text/plain", `{"a":1}`, Options{Pretty: true, Color: true}, `{"a":1}`},
+		{"没有Content-Type", "", `{"a":1}`, Options{Pretty: true, Color: true}, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marked(Body(tt.contentType, tt.body, tt.opts)); got != tt.want {
+				t.Errorf("Body() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		pretty bool
+		color  bool
+		want   string
+	}{
+		{
+			name:   "缩进",
+			input:  `{"name":"张三","tags":["a"],"empty":{},"list":[]}`,
+			pretty: true,
+			want:   "{\n  \"name\": \"张三\",\n  \"tags\": [\n    \"a\"\n  ],\n  \"empty\": {},\n  \"list\": []\n}",
+		},
+		{
+			name:  "着色保留原有格式",
+			input: `{"id": 1234567890123456789, "price": -1.5e3, "ok": true, "none": null, "s": "x"}`,
+			color: true,
+			want:  `{[key]"id"[/]: [num]1234567890123456789[/], [key]"price"[/]: [num]-1.5e3[/], [key]"ok"[/]: [lit]true[/], [key]"none"[/]: [lit]null[/], [key]"s"[/]: [str]"x"[/]}`,
+		},
+		{
+			name:  "字符串中的转义和冒号",
+			input: `["a\"b:", "c" , {"k\\" :"v"}]`,
+			color: true,
+			want:  `[[str]"a\"b:"[/], [str]"c"[/] , {[key]"k\\"[/] :[str]"v"[/]}]`,
+		},
+		{
+			name:   "缩进并着色",
+			input:  `{"a":[1,false]}`,
+			pretty: true,
+			color:  true,
+			want:   "{\n  [key]\"a\"[/]: [\n    [num]1[/],\n    [lit]false[/]\n  ]\n}",
+		},
+		{
+			name:   "无效的JSON原样返回",
+			input:  `{"a":1,}`,
+			pretty: true,
+			color:  true,
+			want:   `{"a":1,}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marked(FormatJSON(tt.input, tt.pretty, tt.color)); got != tt.want {
+				t.Errorf("FormatJSON() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatXML(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		pretty bool
+		color  bool
+		want   string
+	}{
+		{
+			name:   "缩进",
+			input:  `<?xml version="1.0"?><users><!-- 用户 --><user id="1"><name>张三</name><empty/></user></users>`,
+			pretty: true,
+			want:   "<?xml version=\"1.0\"?>\n<users>\n  <!-- 用户 -->\n  <user id=\"1\">\n    <name>张三</name>\n    <empty></empty>\n  </user>\n</users>",
+		},
+		{
+			name:   "命名空间前缀和转义",
+			input:  "<soap:Envelope xmlns:soap=\"urn:x\">\n  <soap:Body a=\"&lt;&amp;\">1 &lt; 2</soap:Body>\n</soap:Envelope>",
+			pretty: true,
+			want:   "<soap:Envelope xmlns:soap=\"urn:x\">\n  <soap:Body a=\"&lt;&amp;\">1 &lt; 2</soap:Body>\n</soap:Envelope>",
+		},
+		{
+			name:   "混合内容",
+			input:  `<p>前<b>粗</b>后</p>`,
+			pretty: true,
+			want:   "<p>前\n  <b>粗</b>\n  后\n</p>",
+		},
+		{
+			name:  "着色",
+			input: `<?xml version="1.0"?><a x='1' y="2"><!-- c --><b/></a>`,
+			color: true,
+			want:  `[cmt]<?xml version="1.0"?>[/][tag]<a[/] [attr]x[/]=[str]'1'[/] [attr]y[/]=[str]"2"[/][tag]>[/][cmt]<!-- c -->[/][tag]<b[/][tag]/>[/][tag]</a[/][tag]>[/]`,
+		},
+		{
+			name:   "无效的XML不缩进",
+			input:  `<a><b>x</b><c`,
+			pretty: true,
+			want:   `<a><b>x</b><c`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marked(FormatXML(tt.input, tt.pretty, tt.color)); got != tt.want {
+				t.Errorf("FormatXML() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"文档类型和属性", `<!DOCTYPE html><a href="/x" hidden>链接</a>`, `[cmt]<!DOCTYPE html>[/][tag]<a[/] [attr]href[/]=[str]"/x"[/] [attr]hidden[/][tag]>[/]链接[tag]</a[/][tag]>[/]`},
+		{"未闭合的注释", `<p>x</p><!-- 未结束`, `[tag]<p[/][tag]>[/]x[tag]</p[/][tag]>[/][cmt]<!-- 未结束[/]`},
+		{"未闭合的属性值", `<a title="x`, `[tag]<a[/] [attr]title[/]=[str]"x[/]`},
+		{"无法识别的字符", `<a =x>`, `[tag]<a[/] =[attr]x[/][tag]>[/]`},
+		{"纯文本", `1 > 0`, `1 > 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marked(FormatHTML(tt.input, true)); got != tt.want {
+				t.Errorf("FormatHTML() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := FormatHTML(`<p>x</p>`, false); got != `<p>x</p>` {
+		t.Errorf("FormatHTML() = %q, 不着色时应原样返回", got)
+	}
+}
+
+func TestColorSupported(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if ColorSupported() {
+		t.Error("设置了NO_COLOR时不应使用颜色")
+	}
+}
@@ -0,0 +1,94 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// FormatJSON 格式化JSON：pretty为true时使用两个空格缩进，color为true时为键、字符串、数字和字面量着色
+// 输入不是合法的JSON时原样返回
+func FormatJSON(input string, pretty bool, color bool) string {
+	if !json.Valid([]byte(input)) {
+		return input
+	}
+
+	text := input
+	if pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(input), "", "  "); err == nil {
+			text = buf.String()
+		}
+	}
+	if !color {
+		return text
+	}
+	return colorizeJSON(text)
+}
+
+// colorizeJSON 为合法的JSON文本着色，保留原有的空白和数字格式
+func colorizeJSON(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := stringEnd(text, i)
+			color := colorString
+			if isObjectKey(text, end) {
+				color = colorKey
+			}
+			paint(&b, color, text[i:end])
+			i = end
+
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789+-.eE", text[end]) >= 0 {
+				end++
+			}
+			paint(&b, colorNumber, text[i:end])
+			i = end
+
+		case c == 't' || c == 'f' || c == 'n':
+			end := i + 1
+			for end < len(text) && text[end] >= 'a' && text[end] <= 'z' {
+				end++
+			}
+			paint(&b, colorLiteral, text[i:end])
+			i = end
+
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// stringEnd 返回从start处的引号开始的JSON字符串结束后的位置
+func stringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// isObjectKey 判断字符串之后（跳过空白）是否紧跟冒号，即该字符串是对象的键
+func isObjectKey(text string, pos int) bool {
+	for ; pos < len(text); pos++ {
+		switch text[pos] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
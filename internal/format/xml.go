@@ -0,0 +1,223 @@
+package format
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// FormatXML 格式化XML：pretty为true时重新缩进，color为true时为标签、属性和注释着色
+// 输入不是合法的XML时不缩进
+func FormatXML(input string, pretty bool, color bool) string {
+	text := input
+	if pretty {
+		if indented, err := indentXML(input); err == nil {
+			text = indented
+		}
+	}
+	if !color {
+		return text
+	}
+	return colorizeMarkup(text)
+}
+
+// FormatHTML 格式化HTML：HTML通常不是合法的XML，因此只着色不缩进
+func FormatHTML(input string, color bool) string {
+	if !color {
+		return input
+	}
+	return colorizeMarkup(input)
+}
+
+// xmlIndenter 按两个空格缩进输出XML
+// 只包含文本的元素保持在一行，如<name>张三</name>
+type xmlIndenter struct {
+	b          strings.Builder
+	depth      int
+	afterStart bool // 刚输出开始标签，尚未换行
+	afterText  bool // 刚在开始标签之后输出文本，尚未换行
+}
+
+// indentXML 使用两个空格重新缩进XML
+func indentXML(input string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(input))
+	decoder.Strict = false
+	w := &xmlIndenter{}
+
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			w.line()
+			w.b.WriteString("<" + qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				w.b.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				xml.EscapeText(&w.b, []byte(attr.Value))
+				w.b.WriteString(`"`)
+			}
+			w.b.WriteString(">")
+			w.depth++
+			w.afterStart = true
+
+		case xml.EndElement:
+			w.depth--
+			if !w.afterStart && !w.afterText {
+				w.line()
+			}
+			w.b.WriteString("</" + qualifiedName(t.Name) + ">")
+			w.afterStart, w.afterText = false, false
+
+		case xml.CharData:
+			text := strings.TrimSpace(string(t))
+			if text == "" {
+				continue
+			}
+			if w.afterStart {
+				xml.EscapeText(&w.b, []byte(text))
+				w.afterStart, w.afterText = false, true
+				continue
+			}
+			w.line()
+			xml.EscapeText(&w.b, []byte(text))
+
+		case xml.Comment:
+			w.line()
+			w.b.WriteString("<!--" + string(t) + "-->")
+
+		case xml.ProcInst:
+			w.line()
+			w.b.WriteString("<?" + strings.TrimSpace(t.Target+" "+string(t.Inst)) + "?>")
+
+		case xml.Directive:
+			w.line()
+			w.b.WriteString("<!" + string(t) + ">")
+		}
+	}
+	return w.b.String(), nil
+}
+
+// line 换行并按当前深度缩进，输出开头不换行
+func (w *xmlIndenter) line() {
+	if w.b.Len() > 0 {
+		w.b.WriteString("\n")
+	}
+	w.b.WriteString(strings.Repeat("  ", w.depth))
+	w.afterStart, w.afterText = false, false
+}
+
+// qualifiedName 返回带前缀的名称，如soap:Envelope
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// colorizeMarkup 为XML/HTML文本着色：标签名、属性名、属性值、注释和声明
+func colorizeMarkup(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] != '<' {
+			end := strings.IndexByte(text[i:], '<')
+			if end < 0 {
+				end = len(text) - i
+			}
+			b.WriteString(text[i : i+end])
+			i += end
+			continue
+		}
+
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest, "-->")
+			end = tokenEnd(rest, end, 3)
+			paint(&b, colorComment, rest[:end])
+			i += end
+
+		case strings.HasPrefix(rest, "<?") || strings.HasPrefix(rest, "<!"):
+			end := tokenEnd(rest, strings.IndexByte(rest, '>'), 1)
+			paint(&b, colorComment, rest[:end])
+			i += end
+
+		default:
+			i += colorizeTag(&b, rest)
+		}
+	}
+	return b.String()
+}
+
+// tokenEnd 返回以长度为size的结束符结尾的片段长度，找不到结束符时到文本末尾
+func tokenEnd(text string, index int, size int) int {
+	if index < 0 {
+		return len(text)
+	}
+	return index + size
+}
+
+// colorizeTag 为text开头的标签着色，返回标签的长度
+func colorizeTag(b *strings.Builder, text string) int {
+	// 标签名，包括<和结束标签的/
+	i := 1
+	if i < len(text) && text[i] == '/' {
+		i++
+	}
+	for i < len(text) && !isMarkupDelimiter(text[i]) {
+		i++
+	}
+	paint(b, colorTag, text[:i])
+
+	for i < len(text) {
+		c := text[i]
+		switch {
+		case c == '>':
+			paint(b, colorTag, ">")
+			return i + 1
+
+		case c == '/' && i+1 < len(text) && text[i+1] == '>':
+			paint(b, colorTag, "/>")
+			return i + 2
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '=':
+			b.WriteByte(c)
+			i++
+
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(text[i+1:], c)
+			end = tokenEnd(text[i+1:], end, 1) + i + 1
+			paint(b, colorString, text[i:end])
+			i = end
+
+		default:
+			start := i
+			for i < len(text) && !isMarkupDelimiter(text[i]) && text[i] != '=' {
+				i++
+			}
+			if i == start {
+				// 无法识别的字符，原样输出，避免死循环
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			paint(b, colorAttribute, text[start:i])
+		}
+	}
+	return len(text)
+}
+
+// isMarkupDelimiter 判断字符是否结束标签名或属性名
+func isMarkupDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '>', '/', '"', '\'':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound 表示JSONPath指向的值不存在
+var ErrNotFound = errors.New("路径不存在")
+
+// Lookup 解析JSON数据并按JSONPath表达式取值
+func Lookup(data []byte, path string) (interface{}, error) {
+	doc, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("响应体不是有效的JSON: %w", err)
+	}
+	return Get(doc, path)
+}
+
+// Decode 解析JSON数据，数字保留为json.Number，避免超过2^53的整数（如ID）丢失精度
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("JSON值之后有多余的内容")
+	}
+	return doc, nil
+}
+
+// Get 按JSONPath表达式从已解析的JSON数据中取值
+// 支持的语法：$、.name、['name']、[index]（负数从末尾开始）以及[*]/.*通配符
+// 通配符匹配对象时按键名排序返回各个值
+func Get(doc interface{}, path string) (interface{}, error) {
+	tokens, err := tokenize(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []interface{}{doc}
+	wildcard := false
+	for _, token := range tokens {
+		next := make([]interface{}, 0, len(current))
+		for _, node := range current {
+			values, err := step(node, token)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, values...)
+		}
+		if token == "*" {
+			wildcard = true
+		}
+		current = next
+	}
+
+	if wildcard {
+		return current, nil
+	}
+	if len(current) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	return current[0], nil
+}
+
+// Stringify 将JSON值转换为字符串，字符串原样返回，其他值序列化为JSON
+func Stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// step 对单个节点执行一步路径访问
+func step(node interface{}, token string) ([]interface{}, error) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if token == "*" {
+			// 按键名排序，保证每次取值的顺序相同
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			values := make([]interface{}, 0, len(v))
+			for _, key := range keys {
+				values = append(values, v[key])
+			}
+			return values, nil
+		}
+		value, ok := v[token]
+		if !ok {
+			return nil, nil
+		}
+		return []interface{}{value}, nil
+
+	case []interface{}:
+		if token == "*" {
+			return v, nil
+		}
+		index, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, nil
+		}
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return nil, nil
+		}
+		return []interface{}{v[index]}, nil
+	}
+
+	return nil, nil
+}
+
+// tokenize 将JSONPath表达式拆分为路径片段
+func tokenize(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath必须以'$'开头: %s", path)
+	}
+
+	tokens := make([]string, 0)
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("无效的JSONPath: %s", path)
+			}
+			tokens = append(tokens, rest[:end])
+			rest = rest[end:]
+
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("无效的JSONPath，缺少']': %s", path)
+			}
+			token := strings.TrimSpace(rest[1:end])
+			token = strings.Trim(token, `'"`)
+			tokens = append(tokens, token)
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("无效的JSONPath: %s", path)
+		}
+	}
+
+	return tokens, nil
+}
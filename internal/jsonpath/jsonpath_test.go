@@ -0,0 +1,98 @@
+package jsonpath
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	data := []byte(`{
+		"id": 1234567890123456789,
+		"price": 12.50,
+		"name": "张三",
+		"active": true,
+		"empty": null,
+		"user.name": "dotted",
+		"tags": ["a", "b", "c"],
+		"items": [{"id": 1}, {"id": 2}],
+		"nested": {"list": [[1, 2], [3]]},
+		"object": {"e": 5, "b": 2, "d": 4, "a": 1, "c": 3}
+	}`)
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr error
+	}{
+		{path: "$", want: ""},
+		{path: "$.id", want: "1234567890123456789"},
+		{path: "$.price", want: "12.50"},
+		{path: "$.name", want: "张三"},
+		{path: "$.active", want: "true"},
+		{path: "$.empty", want: "null"},
+		{path: "$['name']", want: "张三"},
+		{path: `$["user.name"]`, want: "dotted"},
+		{path: "$.tags[0]", want: "a"},
+		{path: "$.tags[-1]", want: "c"},
+		{path: "$.tags", want: `["a","b","c"]`},
+		{path: "$.items[1].id", want: "2"},
+		{path: "$.items[*].id", want: "[1,2]"},
+		{path: "$.items.*.id", want: "[1,2]"},
+		{path: "$.nested.*", want: "[[[1,2],[3]]]"},
+		{path: "$.object.*", want: `[1,2,3,4,5]`},
+		{path: "$.object[*]", want: `[1,2,3,4,5]`},
+		{path: "$.nested.list[0][1]", want: "2"},
+		{path: "$.missing", wantErr: ErrNotFound},
+		{path: "$.tags[3]", wantErr: ErrNotFound},
+		{path: "$.tags.name", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := Lookup(data, tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Lookup() 错误 = %v, 期望 %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup() 错误 = %v", err)
+			}
+			if tt.path == "$" {
+				if _, ok := value.(map[string]interface{}); !ok {
+					t.Errorf("Lookup() = %T, 期望对象", value)
+				}
+				return
+			}
+			if got := Stringify(value); got != tt.want {
+				t.Errorf("Lookup() = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		path    string
+		wantErr string
+	}{
+		{"不是JSON", `<xml/>`, "$.a", "响应体不是有效的JSON"},
+		{"多余的内容", `{"a": 1} {"b": 2}`, "$.a", "多余的内容"},
+		{"缺少$", `{"a": 1}`, "a", "必须以'$'开头"},
+		{"缺少]", `{"a": 1}`, "$.a[0", "缺少']'"},
+		{"空的片段", `{"a": 1}`, "$..a", "无效的JSONPath"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Lookup([]byte(tt.data), tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Lookup() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,21 +1,90 @@
 package models
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
+	"sync"
+	"time"
 )
 
 // HTTPRequest 表示一个HTTP请求
 type HTTPRequest struct {
-	Name           string            // 请求名称
-	Description    string            // 请求描述（来自注释）
-	Method         string            // HTTP方法 (GET, POST, PUT等)
-	URL            *url.URL          // 请求URL
-	Headers        http.Header       // 请求头
-	Body           string            // 请求体内容
-	FormParameters url.Values        // 表单参数
-	Variables      map[string]string // 请求中定义的变量
-	LineNumber     int               // 文件中的行号
+	Name            string            // 请求名称
+	Description     string            // 请求描述（来自注释）
+	Method          string            // HTTP方法 (GET, POST, PUT等)
+	URL             *url.URL          // 请求URL
+	Headers         http.Header       // 请求头
+	Body            string            // 请求体内容
+	BodyFile        string            // 请求体文件路径（< 文件 或 <@ 文件）
+	BodyFileResolve bool              // 是否对请求体文件内容进行变量替换（<@ 文件）
+	MultipartParts  []*MultipartPart  // multipart/form-data请求体的各个部分
+	FormParameters  url.Values        // 表单参数
+	Variables       map[string]string // 请求变量（可由预请求脚本通过request.variables设置）
+	LineNumber      int               // 文件中的行号
+
+	PreRequestScript *Script      // 预请求脚本（< {% ... %} 或 < 脚本文件）
+	ResponseHandler  *Script      // 响应处理脚本（> {% ... %} 或 > 脚本文件）
+	Assertions       []*Assertion // 声明式断言（# @assert）
+
+	ResponseOutput          string // 响应保存路径（>> 文件 或 >>! 文件）
+	ResponseOutputOverwrite bool   // 响应保存路径已存在时是否覆盖（>>! 文件）
+
+	ResponseRefs []string // 参考响应文件路径（<> 文件），最新的在前
+
+	NoRedirect        bool          // 不跟随重定向（# @no-redirect）
+	NoCookieJar       bool          // 不使用Cookie存储（# @no-cookie-jar）
+	Timeout           time.Duration // 请求超时时间（# @timeout），为0时使用默认值
+	ConnectionTimeout time.Duration // 连接超时时间（# @connection-timeout），为0时使用默认值
+
+	Tags map[string]bool // 标签（# @tag），用于按标签筛选请求
+}
+
+// DisplayName 返回用于显示的请求名称，未命名的请求使用"方法 URL"
+func (r *HTTPRequest) DisplayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s %s", r.Method, r.URL)
+}
+
+// AddTag 添加标签
+func (r *HTTPRequest) AddTag(tag string) {
+	if r.Tags == nil {
+		r.Tags = make(map[string]bool)
+	}
+	r.Tags[tag] = true
+}
+
+// HasTag 判断请求是否有指定的标签
+func (r *HTTPRequest) HasTag(tag string) bool {
+	return r.Tags[tag]
+}
+
+// TagNames 返回排序后的标签
+func (r *HTTPRequest) TagNames() []string {
+	tags := make([]string, 0, len(r.Tags))
+	for tag := range r.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// MultipartPart 表示multipart请求体中的一个部分
+type MultipartPart struct {
+	Headers     http.Header // 部分的头，如Content-Disposition、Content-Type
+	Body        string      // 文本内容
+	FilePath    string      // 内容来自的文件路径（< 文件）
+	FileResolve bool        // 是否对文件内容进行变量替换（<@ 文件）
+}
+
+// Script 表示请求关联的JavaScript脚本，可以是内联脚本或外部脚本文件
+type Script struct {
+	Content    string // 内联脚本内容
+	Path       string // 外部脚本文件路径（已相对.http文件解析）
+	LineNumber int    // 脚本在文件中的起始行号
 }
 
 // HTTPFile 表示解析后的HTTP文件
@@ -24,6 +93,11 @@ type HTTPFile struct {
 	Requests        []*HTTPRequest               // 文件中的请求列表
 	GlobalVars      map[string]string            // 全局变量
 	EnvironmentVars map[string]map[string]string // 环境变量 [环境名][变量名]值
+	RuntimeVars     map[string]string            // 运行时变量（由脚本通过client.global设置）
+	DotEnvVars      map[string]string            // .env文件中的变量（通过{{$dotenv NAME}}引用）
+	NamedResponses  map[string]*HTTPResponse     // 已执行的命名请求的响应，供{{name.response...}}引用
+
+	mu sync.RWMutex // 并行执行时保护运行时变量和命名响应
 }
 
 // HTTPResponse 表示HTTP响应
@@ -36,6 +110,93 @@ type HTTPResponse struct {
 	Time       int64        // 请求耗时(毫秒)
 	Request    *HTTPRequest // 原始请求
 	Error      error        // 错误(如果有)
+
+	ScriptError error        // 脚本执行错误(如果有)
+	Tests       []TestResult // 测试和断言结果
+	OutputFile  string       // 响应保存到的文件路径（如果有）
+	Timing      *Timing      // 各阶段耗时
+}
+
+// Timing 表示请求各阶段的耗时，发生重定向时DNS解析、建立连接和TLS握手为各次请求之和
+type Timing struct {
+	DNSLookup        time.Duration // DNS解析
+	Connect          time.Duration // 建立TCP连接
+	TLSHandshake     time.Duration // TLS握手
+	ServerProcessing time.Duration // 请求发送完成到收到响应第一个字节
+	TimeToFirstByte  time.Duration // 开始请求到收到响应第一个字节（首字节时间）
+	ContentTransfer  time.Duration // 收到第一个字节到读取完响应体
+	Total            time.Duration // 开始请求到读取完响应体
+	ConnectionReused bool          // 是否复用了已有连接
+	IdleTime         time.Duration // 复用的连接在复用前的空闲时间
+	RemoteAddr       string        // 服务器地址
+}
+
+// Statistics 表示一组请求执行结果的统计信息，响应时间单位为毫秒
+type Statistics struct {
+	Name      string        // 统计对象的名称，汇总统计为空
+	Total     int           // 请求总数
+	Succeeded int           // 成功数（没有网络错误且状态码小于400）
+	Failed    int           // 失败数
+	Elapsed   time.Duration // 执行总耗时
+	MinTime   int64         // 最短响应时间
+	AvgTime   float64       // 平均响应时间
+	P50Time   int64         // 50%分位响应时间
+	P90Time   int64         // 90%分位响应时间
+	P99Time   int64         // 99%分位响应时间
+	MaxTime   int64         // 最长响应时间
+	Requests  []*Statistics // 按请求分组的统计
+}
+
+// SuccessRate 返回成功率（百分比）
+func (s *Statistics) SuccessRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Succeeded) * 100 / float64(s.Total)
+}
+
+// Throughput 返回吞吐量（每秒请求数）
+func (s *Statistics) Throughput() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Total) / s.Elapsed.Seconds()
+}
+
+// Assertion 表示一个声明式断言，例如 # @assert jsonpath $.code == 0
+type Assertion struct {
+	Expression string // 原始断言表达式
+	Subject    string // 断言对象：status、jsonpath、header、time、body
+	Target     string // 断言对象参数，如JSONPath表达式或响应头名称
+	Operator   string // 比较运算符
+	Expected   string // 期望值
+	LineNumber int    // 断言在文件中的行号
+}
+
+// TestResult 表示一个测试或断言的执行结果
+type TestResult struct {
+	Name    string // 测试名称
+	Passed  bool   // 是否通过
+	Message string // 失败原因
+}
+
+// AddTestResult 添加一个测试结果
+func (r *HTTPResponse) AddTestResult(name string, passed bool, message string) {
+	r.Tests = append(r.Tests, TestResult{Name: name, Passed: passed, Message: message})
+}
+
+// FailedTests 返回失败的测试数量，脚本执行错误也计为一个失败
+func (r *HTTPResponse) FailedTests() int {
+	failed := 0
+	for _, t := range r.Tests {
+		if !t.Passed {
+			failed++
+		}
+	}
+	if r.ScriptError != nil {
+		failed++
+	}
+	return failed
 }
 
 // NewHTTPFile 创建一个新的HTTP文件结构
@@ -45,6 +206,7 @@ func NewHTTPFile(path string) *HTTPFile {
 		Requests:        make([]*HTTPRequest, 0),
 		GlobalVars:      make(map[string]string),
 		EnvironmentVars: make(map[string]map[string]string),
+		RuntimeVars:     make(map[string]string),
 	}
 }
 
@@ -63,8 +225,71 @@ func (f *HTTPFile) FindRequestByName(name string) *HTTPRequest {
 	return nil
 }
 
+// SetRuntimeVar 设置运行时变量
+func (f *HTTPFile) SetRuntimeVar(name, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.RuntimeVars == nil {
+		f.RuntimeVars = make(map[string]string)
+	}
+	f.RuntimeVars[name] = value
+}
+
+// RuntimeVar 获取运行时变量
+func (f *HTTPFile) RuntimeVar(name string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	val, ok := f.RuntimeVars[name]
+	return val, ok
+}
+
+// HasRuntimeVars 判断是否存在运行时变量
+func (f *HTTPFile) HasRuntimeVars() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.RuntimeVars) > 0
+}
+
+// DeleteRuntimeVar 删除运行时变量
+func (f *HTTPFile) DeleteRuntimeVar(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.RuntimeVars, name)
+}
+
+// ClearRuntimeVars 清空所有运行时变量
+func (f *HTTPFile) ClearRuntimeVars() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.RuntimeVars = make(map[string]string)
+}
+
+// SetNamedResponse 记录命名请求的响应
+func (f *HTTPFile) SetNamedResponse(name string, resp *HTTPResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.NamedResponses == nil {
+		f.NamedResponses = make(map[string]*HTTPResponse)
+	}
+	f.NamedResponses[name] = resp
+}
+
+// NamedResponse 获取命名请求的响应
+func (f *HTTPFile) NamedResponse(name string) (*HTTPResponse, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	resp, ok := f.NamedResponses[name]
+	return resp, ok
+}
+
 // ResolveVariable 解析变量，支持环境变量替换
+// 查找顺序：运行时变量 > 环境变量 > 全局变量
 func (f *HTTPFile) ResolveVariable(name string, env string) (string, bool) {
+	// 脚本设置的运行时变量优先，这样登录等请求的结果可以覆盖环境文件中的占位值
+	if val, ok := f.RuntimeVar(name); ok {
+		return val, true
+	}
+
 	// 首先查找环境变量
 	if env != "" {
 		if envVars, ok := f.EnvironmentVars[env]; ok {
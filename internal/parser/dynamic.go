@@ -0,0 +1,249 @@
+package parser
+
+import (
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 动态变量正则表达式：$名称 或 $名称(参数1, 参数2)
+var dynamicVariableRegex = regexp.MustCompile(`^\$([\w.]+)\s*(?:\((.*)\))?$`)
+
+// 字符集定义
+const (
+	alphabeticChars   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	alphanumericChars = alphabeticChars + "0123456789"
+	hexadecimalChars  = "0123456789abcdef"
+)
+
+// Faker风格生成器使用的示例数据
+var (
+	firstNames  = []string{"James", "Mary", "John", "Linda", "Robert", "Emma", "Michael", "Olivia", "David", "Sophia", "Wei", "Fang", "Lei", "Min"}
+	lastNames   = []string{"Smith", "Johnson", "Brown", "Garcia", "Miller", "Wilson", "Taylor", "Anderson", "Wang", "Li", "Zhang", "Liu", "Chen"}
+	domainWords = []string{"example", "test", "demo", "sample", "acme", "mail", "corp"}
+	domainTLDs  = []string{"com", "net", "org", "io", "cn"}
+	cities      = []string{"Beijing", "Shanghai", "Shenzhen", "London", "Paris", "Berlin", "Tokyo", "New York", "Sydney", "Toronto"}
+	countries   = []string{"China", "United States", "United Kingdom", "France", "Germany", "Japan", "Australia", "Canada"}
+	streets     = []string{"Main Street", "Park Avenue", "Oak Road", "Maple Lane", "River Road", "Sunset Boulevard"}
+	companies   = []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli", "Stark", "Wayne", "Wonka"}
+	companySufs = []string{"Inc", "LLC", "Group", "Ltd", "Corp"}
+	loremWords  = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor", "incididunt", "labore", "magna", "aliqua"}
+	colors      = []string{"red", "green", "blue", "yellow", "purple", "orange", "black", "white"}
+)
+
+// dynamicGenerator 动态变量生成函数
+type dynamicGenerator func(args []string) (string, error)
+
+// dynamicGenerators 支持的动态变量，每次引用都会重新生成
+var dynamicGenerators = map[string]dynamicGenerator{
+	"uuid":         noArgs(uuidV4),
+	"timestamp":    noArgs(func() string { return strconv.FormatInt(time.Now().Unix(), 10) }),
+	"isoTimestamp": noArgs(func() string { return time.Now().UTC().Format("2006-01-02T15:04:05.000Z") }),
+	"randomInt":    noArgs(func() string { return strconv.Itoa(mathrand.Intn(1001)) }),
+
+	"random.uuid":         noArgs(uuidV4),
+	"random.integer":      randomInteger,
+	"random.float":        randomFloat,
+	"random.alphabetic":   randomString(alphabeticChars),
+	"random.alphanumeric": randomString(alphanumericChars),
+	"random.hexadecimal":  randomString(hexadecimalChars),
+	"random.email":        noArgs(randomEmail),
+	"random.bool":         noArgs(func() string { return strconv.FormatBool(mathrand.Intn(2) == 1) }),
+
+	"random.name.firstName":       noArgs(func() string { return pick(firstNames) }),
+	"random.name.lastName":        noArgs(func() string { return pick(lastNames) }),
+	"random.name.fullName":        noArgs(func() string { return pick(firstNames) + " " + pick(lastNames) }),
+	"random.name.username":        noArgs(randomUsername),
+	"random.internet.email":       noArgs(randomEmail),
+	"random.internet.domainName":  noArgs(randomDomain),
+	"random.internet.url":         noArgs(func() string { return "https://www." + randomDomain() }),
+	"random.internet.ipV4Address": noArgs(randomIPv4),
+	"random.internet.uuid":        noArgs(uuidV4),
+	"random.phone.cellPhone":      noArgs(func() string { return "1" + randomDigits(10) }),
+	"random.address.city":         noArgs(func() string { return pick(cities) }),
+	"random.address.country":      noArgs(func() string { return pick(countries) }),
+	"random.address.streetAddress": noArgs(func() string {
+		return fmt.Sprintf("%d %s", mathrand.Intn(9999)+1, pick(streets))
+	}),
+	"random.address.zipCode": noArgs(func() string { return randomDigits(6) }),
+	"random.company.name":    noArgs(func() string { return pick(companies) + " " + pick(companySufs) }),
+	"random.color.name":      noArgs(func() string { return pick(colors) }),
+	"random.lorem.word":      noArgs(func() string { return pick(loremWords) }),
+	"random.lorem.sentence":  noArgs(randomSentence),
+}
+
+// resolveDynamicVariable 解析动态变量，如 $uuid、$random.integer(1, 100)
+// 返回false表示不是已知的动态变量
+func resolveDynamicVariable(expr string) (string, bool, error) {
+	matches := dynamicVariableRegex.FindStringSubmatch(strings.TrimSpace(expr))
+	if matches == nil {
+		return "", false, nil
+	}
+
+	generator, ok := dynamicGenerators[matches[1]]
+	if !ok {
+		return "", false, nil
+	}
+
+	var args []string
+	if strings.TrimSpace(matches[2]) != "" {
+		for _, arg := range strings.Split(matches[2], ",") {
+			args = append(args, strings.TrimSpace(arg))
+		}
+	}
+
+	value, err := generator(args)
+	if err != nil {
+		return "", true, fmt.Errorf("动态变量 '%s': %w", expr, err)
+	}
+	return value, true, nil
+}
+
+// noArgs 包装不需要参数的生成函数
+func noArgs(fn func() string) dynamicGenerator {
+	return func(args []string) (string, error) {
+		if len(args) > 0 {
+			return "", fmt.Errorf("不需要参数")
+		}
+		return fn(), nil
+	}
+}
+
+// randomInteger 生成[from, to)范围内的随机整数，默认范围为[0, 1000)，上下限必须是整数
+func randomInteger(args []string) (string, error) {
+	from, to, err := parseIntegerRange(args, 0, 1000)
+	if err != nil {
+		return "", err
+	}
+	if to <= from {
+		return "", fmt.Errorf("上限必须大于下限")
+	}
+	// 上下限相差超过int64的范围时相减会溢出
+	span := to - from
+	if span <= 0 {
+		return "", fmt.Errorf("范围过大")
+	}
+	return strconv.FormatInt(from+mathrand.Int63n(span), 10), nil
+}
+
+// randomFloat 生成[from, to)范围内的随机浮点数，默认范围为[0, 1000)
+func randomFloat(args []string) (string, error) {
+	from, to, err := parseRange(args, 0, 1000)
+	if err != nil {
+		return "", err
+	}
+	if to <= from {
+		return "", fmt.Errorf("上限必须大于下限")
+	}
+	return strconv.FormatFloat(from+mathrand.Float64()*(to-from), 'f', -1, 64), nil
+}
+
+// parseRange 解析范围参数
+func parseRange(args []string, defaultFrom, defaultTo float64) (float64, float64, error) {
+	switch len(args) {
+	case 0:
+		return defaultFrom, defaultTo, nil
+	case 2:
+		from, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("无效的下限 '%s'", args[0])
+		}
+		to, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("无效的上限 '%s'", args[1])
+		}
+		return from, to, nil
+	}
+	return 0, 0, fmt.Errorf("需要两个参数(下限, 上限)")
+}
+
+// parseIntegerRange 解析整数范围参数
+func parseIntegerRange(args []string, defaultFrom, defaultTo int64) (int64, int64, error) {
+	switch len(args) {
+	case 0:
+		return defaultFrom, defaultTo, nil
+	case 2:
+		from, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("无效的下限 '%s'，需要整数", args[0])
+		}
+		to, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("无效的上限 '%s'，需要整数", args[1])
+		}
+		return from, to, nil
+	}
+	return 0, 0, fmt.Errorf("需要两个参数(下限, 上限)")
+}
+
+// randomString 返回从指定字符集生成随机字符串的函数，参数为长度
+func randomString(charset string) dynamicGenerator {
+	return func(args []string) (string, error) {
+		if len(args) != 1 {
+			return "", fmt.Errorf("需要一个参数(长度)")
+		}
+		length, err := strconv.Atoi(args[0])
+		if err != nil || length < 0 {
+			return "", fmt.Errorf("无效的长度 '%s'", args[0])
+		}
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = charset[mathrand.Intn(len(charset))]
+		}
+		return string(b), nil
+	}
+}
+
+// uuidV4 生成随机UUID（版本4）
+func uuidV4() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		for i := range b {
+			b[i] = byte(mathrand.Intn(256))
+		}
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func pick(values []string) string {
+	return values[mathrand.Intn(len(values))]
+}
+
+func randomDigits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + mathrand.Intn(10))
+	}
+	return string(b)
+}
+
+func randomUsername() string {
+	return strings.ToLower(pick(firstNames)) + "." + strings.ToLower(pick(lastNames)) + randomDigits(2)
+}
+
+func randomDomain() string {
+	return pick(domainWords) + "." + pick(domainTLDs)
+}
+
+func randomEmail() string {
+	return randomUsername() + "@" + randomDomain()
+}
+
+func randomIPv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", mathrand.Intn(223)+1, mathrand.Intn(256), mathrand.Intn(256), mathrand.Intn(254)+1)
+}
+
+func randomSentence() string {
+	words := make([]string, 6+mathrand.Intn(6))
+	for i := range words {
+		words[i] = pick(loremWords)
+	}
+	sentence := strings.Join(words, " ")
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResolveDynamicVariable(t *testing.T) {
+	tests := []struct {
+		expr  string
+		check func(value string) bool
+	}{
+		{"$uuid", regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString},
+		{"$random.uuid", regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-`).MatchString},
+		{"$timestamp", regexp.MustCompile(`^\d{10}$`).MatchString},
+		{"$isoTimestamp", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z$`).MatchString},
+		{"$randomInt", intBetween(0, 1000)},
+		{"$random.integer", intBetween(0, 999)},
+		{"$random.integer(5, 6)", intBetween(5, 5)},
+		{"$random.integer(-10, -5)", intBetween(-10, -6)},
+		{" $random.integer( 1 , 3 ) ", intBetween(1, 2)},
+		{"$random.integer(-9223372036854775807, 0)", intBetween(-9223372036854775807, -1)},
+		{"$random.float(1.5, 1.6)", floatBetween(1.5, 1.6)},
+		{"$random.float", floatBetween(0, 1000)},
+		{"$random.alphabetic(8)", regexp.MustCompile(`^[a-zA-Z]{8}$`).MatchString},
+		{"$random.alphanumeric(0)", func(v string) bool { return v == "" }},
+		{"$random.hexadecimal(16)", regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString},
+		{"$random.bool", func(v string) bool { return v == "true" || v == "false" }},
+		{"$random.email", regexp.MustCompile(`^[a-z]+\.[a-z]+\d{2}@[a-z]+\.[a-z]+$`).MatchString},
+		{"$random.internet.ipV4Address", regexp.MustCompile(`^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}$`).MatchString},
+		{"$random.phone.cellPhone", regexp.MustCompile(`^1\d{10}$`).MatchString},
+		{"$random.address.zipCode", regexp.MustCompile(`^\d{6}$`).MatchString},
+		{"$random.lorem.sentence", regexp.MustCompile(`^[A-Z][a-z ]+\.$`).MatchString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				value, ok, err := resolveDynamicVariable(tt.expr)
+				if err != nil || !ok {
+					t.Fatalf("resolveDynamicVariable() = %q, %v, %v", value, ok, err)
+				}
+				if !tt.check(value) {
+					t.Fatalf("resolveDynamicVariable() = %q", value)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveDynamicVariableErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		known   bool
+		wantErr string
+	}{
+		{expr: "$unknown", known: false},
+		{expr: "token", known: false},
+		{expr: "$random.integer(1, 1.5)", known: true, wantErr: "无效的上限 '1.5'，需要整数"},
+		{expr: "$random.integer(0.5, 10)", known: true, wantErr: "无效的下限 '0.5'，需要整数"},
+		{expr: "$random.integer(5, 5)", known: true, wantErr: "上限必须大于下限"},
+		{expr: "$random.integer(10, 1)", known: true, wantErr: "上限必须大于下限"},
+		{expr: "$random.integer(-9223372036854775808, 9223372036854775807)", known: true, wantErr: "范围过大"},
+		{expr: "$random.integer(1)", known: true, wantErr: "需要两个参数"},
+		{expr: "$random.float(a, 2)", known: true, wantErr: "无效的下限 'a'"},
+		{expr: "$random.float(2, 2)", known: true, wantErr: "上限必须大于下限"},
+		{expr: "$random.alphabetic", known: true, wantErr: "需要一个参数"},
+		{expr: "$random.alphabetic(-1)", known: true, wantErr: "无效的长度"},
+		{expr: "$uuid(1)", known: true, wantErr: "不需要参数"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, known, err := resolveDynamicVariable(tt.expr)
+			if known != tt.known {
+				t.Errorf("known = %v, 期望 %v", known, tt.known)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("错误 = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), tt.expr) {
+				t.Errorf("错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// intBetween 返回判断值是否为[from, to]范围内整数的函数
+func intBetween(from, to int64) func(string) bool {
+	return func(value string) bool {
+		n, err := strconv.ParseInt(value, 10, 64)
+		return err == nil && n >= from && n <= to
+	}
+}
+
+// floatBetween 返回判断值是否为[from, to)范围内数字的函数
+func floatBetween(from, to float64) func(string) bool {
+	return func(value string) bool {
+		f, err := strconv.ParseFloat(value, 64)
+		return err == nil && f >= from && f < to
+	}
+}
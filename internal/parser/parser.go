@@ -3,12 +3,18 @@ package parser
 import (
 	"bufio"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/shellus/jhttp/internal/assertion"
 	"github.com/shellus/jhttp/internal/models"
 )
 
@@ -17,8 +23,8 @@ var (
 	// 请求行正则表达式：方法 + URL
 	requestLineRegex = regexp.MustCompile(`^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|TRACE)\s+(.+)$`)
 
-	// 请求名称正则表达式：以###开头的行
-	requestNameRegex = regexp.MustCompile(`^###\s*(.+)$`)
+	// 请求名称正则表达式：以###开头的行，名称可以为空
+	requestNameRegex = regexp.MustCompile(`^###(.*)$`)
 
 	// 变量定义正则表达式：@变量名 = 变量值
 	variableRegex = regexp.MustCompile(`^@(\w+)\s*=\s*(.+)$`)
@@ -28,8 +34,119 @@ var (
 
 	// 变量引用正则表达式：{{变量名}}
 	variableRefRegex = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+
+	// 指令正则表达式：# @指令名 参数
+	directiveRegex = regexp.MustCompile(`^#\s*@([\w-]+)\s*(.*)$`)
+
+	// 响应保存正则表达式：>> 文件路径（冲突时自动重命名）或 >>! 文件路径（覆盖）
+	responseOutputRegex = regexp.MustCompile(`^>>(!)?\s+(.+)$`)
+
+	// 参考响应正则表达式：<> 之前保存的响应文件
+	responseRefRegex = regexp.MustCompile(`^<>\s+(.+)$`)
+
+	// 响应处理脚本正则表达式：> {% 脚本 %} 或 > 脚本文件路径
+	responseHandlerRegex = regexp.MustCompile(`^>\s+(.+)$`)
+
+	// 预请求脚本正则表达式：< {% 脚本 %} 或 < 脚本文件路径（位于请求行之前）
+	preRequestScriptRegex = regexp.MustCompile(`^<\s+(.+)$`)
+
+	// 请求体文件正则表达式：< 文件路径 或 <@ 文件路径（带变量替换）
+	bodyFileRegex = regexp.MustCompile(`^<(@)?\s+(.+)$`)
 )
 
+// 内联脚本的起止标记
+const (
+	scriptStartMarker = "{%"
+	scriptEndMarker   = "%}"
+)
+
+// directive 表示一条请求指令（# @name value）
+type directive struct {
+	name       string
+	value      string
+	lineNumber int
+}
+
+// directiveHandlers 已知指令的处理函数，未知指令（如@deprecated）仍作为请求描述保留
+var directiveHandlers = map[string]func(req *models.HTTPRequest, d directive) error{
+	"assert": func(req *models.HTTPRequest, d directive) error {
+		a, err := assertion.Parse(d.value)
+		if err != nil {
+			return err
+		}
+		a.LineNumber = d.lineNumber
+		req.Assertions = append(req.Assertions, a)
+		return nil
+	},
+	"name": func(req *models.HTTPRequest, d directive) error {
+		if d.value == "" {
+			return fmt.Errorf("请求名称不能为空")
+		}
+		req.Name = d.value
+		return nil
+	},
+	"no-redirect": func(req *models.HTTPRequest, d directive) error {
+		req.NoRedirect = true
+		return nil
+	},
+	"no-cookie-jar": func(req *models.HTTPRequest, d directive) error {
+		req.NoCookieJar = true
+		return nil
+	},
+	"tag": func(req *models.HTTPRequest, d directive) error {
+		tags := splitTags(d.value)
+		if len(tags) == 0 {
+			return fmt.Errorf("标签不能为空")
+		}
+		for _, tag := range tags {
+			req.AddTag(tag)
+		}
+		return nil
+	},
+	"timeout": func(req *models.HTTPRequest, d directive) error {
+		timeout, err := parseDirectiveDuration(d.value)
+		if err != nil {
+			return err
+		}
+		req.Timeout = timeout
+		return nil
+	},
+	"connection-timeout": func(req *models.HTTPRequest, d directive) error {
+		timeout, err := parseDirectiveDuration(d.value)
+		if err != nil {
+			return err
+		}
+		req.ConnectionTimeout = timeout
+		return nil
+	},
+}
+
+// splitTags 拆分用逗号或空白分隔的标签
+func splitTags(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// parseDirectiveDuration 解析指令中的时间，纯数字表示秒，也可以带单位，如 500ms、2m
+func parseDirectiveDuration(value string) (time.Duration, error) {
+	value = strings.ReplaceAll(value, " ", "")
+	if value == "" {
+		return 0, fmt.Errorf("缺少时间参数")
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0, fmt.Errorf("时间必须大于0: %s", value)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("无效的时间 '%s'", value)
+	}
+	return d, nil
+}
+
 // ParseFile 解析HTTP文件
 func ParseFile(filePath string) (*models.HTTPFile, error) {
 	file, err := os.Open(filePath)
@@ -50,12 +167,33 @@ func ParseFile(filePath string) (*models.HTTPFile, error) {
 	var currentDescription string
 	var readingRequestComment bool      // 用于标记是否正在读取请求注释
 	var foundEmptyLineAfterHeaders bool // 用于标记是否找到了请求头之后的空行
+	var currentScript *models.Script    // 正在读取的多行内联脚本
+	var scriptBuilder strings.Builder
+	var preRequestScript *models.Script // 等待关联到下一个请求的预请求脚本
+	var requestInBlock bool             // 当前请求块（以###分隔）中是否已经出现请求行
+	var pendingDirectives []directive   // 等待应用到下一个请求的指令
+
+	baseDir := filepath.Dir(filePath)
 
 	// 逐行解析文件
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 
+		// 如果正在读取多行内联脚本，直到遇到结束标记为止
+		if currentScript != nil {
+			if idx := strings.Index(line, scriptEndMarker); idx >= 0 {
+				scriptBuilder.WriteString(line[:idx])
+				currentScript.Content = strings.TrimSpace(scriptBuilder.String())
+				currentScript = nil
+				scriptBuilder.Reset()
+			} else {
+				scriptBuilder.WriteString(line)
+				scriptBuilder.WriteString("\n")
+			}
+			continue
+		}
+
 		// 跳过空行
 		if line == "" {
 			if currentRequest != nil && currentRequest.Method != "" {
@@ -76,19 +214,36 @@ func ParseFile(filePath string) (*models.HTTPFile, error) {
 		if matches := requestNameRegex.FindStringSubmatch(line); len(matches) > 1 {
 			// 如果上一个请求还在处理中，保存其请求体
 			if isReadingBody && currentRequest != nil {
-				currentRequest.Body = strings.TrimSpace(bodyBuilder.String())
+				setRequestBody(currentRequest, bodyBuilder.String(), baseDir)
 				isReadingBody = false
 				bodyBuilder.Reset()
 			}
 
 			// 保存当前请求名（不再包含注释）
-			currentName = matches[1]
+			currentName = strings.TrimSpace(matches[1])
+			requestInBlock = false
+			pendingDirectives = nil
 			currentDescription = ""            // 重置描述
 			readingRequestComment = true       // 标记正在读取请求注释
 			foundEmptyLineAfterHeaders = false // 重置标记
 			continue
 		}
 
+		// 处理指令行，出现在请求行之前时暂存，之后直接应用到当前请求
+		if matches := directiveRegex.FindStringSubmatch(line); len(matches) > 2 {
+			if _, known := directiveHandlers[matches[1]]; known {
+				d := directive{name: matches[1], value: strings.TrimSpace(matches[2]), lineNumber: lineNum}
+				if requestInBlock && currentRequest != nil {
+					if err := applyDirective(currentRequest, d); err != nil {
+						return nil, err
+					}
+				} else {
+					pendingDirectives = append(pendingDirectives, d)
+				}
+				continue
+			}
+		}
+
 		// 处理注释行，现在注释内容不会添加到请求名中
 		if strings.HasPrefix(line, "#") {
 			if readingRequestComment {
@@ -112,6 +267,20 @@ func ParseFile(filePath string) (*models.HTTPFile, error) {
 			continue
 		}
 
+		// 处理预请求脚本，只在请求行之前出现
+		if matches := preRequestScriptRegex.FindStringSubmatch(line); len(matches) > 1 && !requestInBlock {
+			script, complete := parseScript(matches[1], lineNum, baseDir)
+			if !complete {
+				currentScript = script
+				scriptBuilder.Reset()
+				scriptBuilder.WriteString(script.Content)
+				scriptBuilder.WriteString("\n")
+			}
+			preRequestScript = script
+			readingRequestComment = false
+			continue
+		}
+
 		// 处理请求行（方法+URL）
 		if matches := requestLineRegex.FindStringSubmatch(line); len(matches) > 2 {
 			method, rawURL := matches[1], matches[2]
@@ -129,11 +298,22 @@ func ParseFile(filePath string) (*models.HTTPFile, error) {
 				Headers:     make(http.Header),
 				Variables:   make(map[string]string),
 				LineNumber:  lineNum,
+
+				PreRequestScript: preRequestScript,
 			}
 			httpFile.AddRequest(currentRequest)
 
+			for _, d := range pendingDirectives {
+				if err := applyDirective(currentRequest, d); err != nil {
+					return nil, err
+				}
+			}
+
 			// 重置状态
 			currentName = ""
+			preRequestScript = nil
+			pendingDirectives = nil
+			requestInBlock = true
 			currentDescription = ""
 			readingRequestComment = false
 			isReadingBody = false
@@ -141,6 +321,70 @@ func ParseFile(filePath string) (*models.HTTPFile, error) {
 			continue
 		}
 
+		// 处理响应保存（>> ./out.json 或 >>! ./out.json），与响应处理脚本一样位于请求体之后
+		if matches := responseOutputRegex.FindStringSubmatch(line); len(matches) > 2 && currentRequest != nil {
+			if isReadingBody {
+				setRequestBody(currentRequest, bodyBuilder.String(), baseDir)
+				isReadingBody = false
+				bodyBuilder.Reset()
+			}
+			foundEmptyLineAfterHeaders = true
+
+			currentRequest.ResponseOutput = resolvePath(baseDir, strings.TrimSpace(matches[2]))
+			currentRequest.ResponseOutputOverwrite = matches[1] == "!"
+			readingRequestComment = false
+			continue
+		}
+
+		// 处理参考响应（<> ./previous.json），位于请求体之后
+		if matches := responseRefRegex.FindStringSubmatch(line); len(matches) > 1 && currentRequest != nil {
+			if isReadingBody {
+				setRequestBody(currentRequest, bodyBuilder.String(), baseDir)
+				isReadingBody = false
+				bodyBuilder.Reset()
+			}
+			foundEmptyLineAfterHeaders = true
+
+			currentRequest.ResponseRefs = append(currentRequest.ResponseRefs, resolvePath(baseDir, strings.TrimSpace(matches[1])))
+			readingRequestComment = false
+			continue
+		}
+
+		// 处理响应处理脚本（> {% ... %} 或 > ./handler.js）
+		if matches := responseHandlerRegex.FindStringSubmatch(line); len(matches) > 1 && currentRequest != nil {
+			// 响应处理脚本位于请求体之后，遇到时结束请求体
+			if isReadingBody {
+				setRequestBody(currentRequest, bodyBuilder.String(), baseDir)
+				isReadingBody = false
+				bodyBuilder.Reset()
+			}
+			foundEmptyLineAfterHeaders = true
+
+			script, complete := parseScript(matches[1], lineNum, baseDir)
+			if !complete {
+				// 多行内联脚本，继续读取后续行
+				currentScript = script
+				scriptBuilder.Reset()
+				scriptBuilder.WriteString(script.Content)
+				scriptBuilder.WriteString("\n")
+			}
+			currentRequest.ResponseHandler = script
+			readingRequestComment = false
+			continue
+		}
+
+		// 处理请求体文件（< ./body.json 或 <@ ./body.json）
+		// multipart请求体中的文件引用属于各个部分，在请求体结束时统一解析
+		if matches := bodyFileRegex.FindStringSubmatch(line); len(matches) > 2 && requestInBlock && currentRequest != nil &&
+			multipartBoundary(currentRequest.Headers.Get("Content-Type")) == "" {
+			currentRequest.BodyFile = resolvePath(baseDir, strings.TrimSpace(matches[2]))
+			currentRequest.BodyFileResolve = matches[1] == "@"
+			isReadingBody = true
+			foundEmptyLineAfterHeaders = true
+			readingRequestComment = false
+			continue
+		}
+
 		// 处理请求头
 		if matches := headerRegex.FindStringSubmatch(line); len(matches) > 2 && currentRequest != nil && !isReadingBody {
 			name, value := matches[1], matches[2]
@@ -173,16 +417,146 @@ func ParseFile(filePath string) (*models.HTTPFile, error) {
 
 	// 处理最后一个请求的请求体
 	if isReadingBody && currentRequest != nil {
-		currentRequest.Body = strings.TrimSpace(bodyBuilder.String())
+		setRequestBody(currentRequest, bodyBuilder.String(), baseDir)
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("读取文件时发生错误: %w", err)
 	}
 
+	if currentScript != nil {
+		return nil, fmt.Errorf("行 %d: 脚本缺少结束标记 '%s'", currentScript.LineNumber, scriptEndMarker)
+	}
+
 	return httpFile, nil
 }
 
+// applyDirective 将指令应用到请求
+func applyDirective(req *models.HTTPRequest, d directive) error {
+	if err := directiveHandlers[d.name](req, d); err != nil {
+		return fmt.Errorf("行 %d: 无效的@%s指令: %w", d.lineNumber, d.name, err)
+	}
+	return nil
+}
+
+// setRequestBody 设置请求体，multipart/form-data请求体会被拆分为各个部分
+func setRequestBody(req *models.HTTPRequest, body string, baseDir string) {
+	req.Body = strings.TrimSpace(body)
+
+	if boundary := multipartBoundary(req.Headers.Get("Content-Type")); boundary != "" {
+		req.MultipartParts = parseMultipartBody(req.Body, boundary, baseDir)
+	}
+}
+
+// multipartBoundary 返回multipart请求的分隔符，非multipart请求返回空字符串
+func multipartBoundary(contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return ""
+	}
+	return params["boundary"]
+}
+
+// parseMultipartBody 解析multipart请求体，格式如下：
+//
+//	--boundary
+//	Content-Disposition: form-data; name="field"
+//
+//	value
+//	--boundary
+//	Content-Disposition: form-data; name="file"; filename="a.png"
+//
+//	< ./a.png
+//	--boundary--
+func parseMultipartBody(body, boundary, baseDir string) []*models.MultipartPart {
+	delimiter := "--" + boundary
+	parts := make([]*models.MultipartPart, 0)
+
+	var current *models.MultipartPart
+	var readingHeaders bool
+	var content []string
+
+	finishPart := func() {
+		if current != nil && current.FilePath == "" {
+			current.Body = strings.Join(content, "\n")
+		}
+		content = nil
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// 分隔符：开始新的部分或结束请求体
+		if trimmed == delimiter || trimmed == delimiter+"--" {
+			finishPart()
+			current = nil
+			if trimmed == delimiter {
+				current = &models.MultipartPart{Headers: make(http.Header)}
+				parts = append(parts, current)
+				readingHeaders = true
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		// 部分的头，直到遇到空行
+		if readingHeaders {
+			if trimmed == "" {
+				readingHeaders = false
+				continue
+			}
+			if matches := headerRegex.FindStringSubmatch(line); len(matches) > 2 {
+				current.Headers.Add(matches[1], matches[2])
+			}
+			continue
+		}
+
+		// 部分的内容可以引用文件
+		if matches := bodyFileRegex.FindStringSubmatch(line); len(matches) > 2 && current.FilePath == "" && len(content) == 0 {
+			current.FilePath = resolvePath(baseDir, strings.TrimSpace(matches[2]))
+			current.FileResolve = matches[1] == "@"
+			continue
+		}
+		content = append(content, line)
+	}
+	finishPart()
+
+	return parts
+}
+
+// parseScript 解析脚本标记后的内容，可以是内联脚本（{% ... %}）或脚本文件路径
+// 返回的complete为false时表示内联脚本跨越多行，Content中为首行已读取的部分
+func parseScript(content string, lineNum int, baseDir string) (*models.Script, bool) {
+	script := &models.Script{LineNumber: lineNum}
+	content = strings.TrimSpace(content)
+
+	if !strings.HasPrefix(content, scriptStartMarker) {
+		script.Path = resolvePath(baseDir, content)
+		return script, true
+	}
+
+	content = content[len(scriptStartMarker):]
+	if idx := strings.Index(content, scriptEndMarker); idx >= 0 {
+		// 单行内联脚本
+		script.Content = strings.TrimSpace(content[:idx])
+		return script, true
+	}
+
+	script.Content = content
+	return script, false
+}
+
+// resolvePath 将.http文件中引用的相对路径解析为相对于.http文件所在目录的路径
+func resolvePath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
 // ResolveVariables 解析HTTP请求中的变量引用
 func ResolveVariables(httpFile *models.HTTPFile, request *models.HTTPRequest, env string) (*models.HTTPRequest, error) {
 	// 创建请求的副本
@@ -194,8 +568,25 @@ func ResolveVariables(httpFile *models.HTTPFile, request *models.HTTPRequest, en
 		FormParameters: request.FormParameters,
 		Variables:      make(map[string]string),
 		LineNumber:     request.LineNumber,
+
+		PreRequestScript:        request.PreRequestScript,
+		ResponseHandler:         request.ResponseHandler,
+		ResponseOutputOverwrite: request.ResponseOutputOverwrite,
+		ResponseRefs:            request.ResponseRefs,
+		NoRedirect:              request.NoRedirect,
+		NoCookieJar:             request.NoCookieJar,
+		Timeout:                 request.Timeout,
+		ConnectionTimeout:       request.ConnectionTimeout,
+		Tags:                    request.Tags,
 	}
 
+	// 复制请求变量（可能由预请求脚本设置）
+	for name, value := range request.Variables {
+		resolvedReq.Variables[name] = value
+	}
+
+	r := &resolver{httpFile: httpFile, vars: request.Variables, env: env}
+
 	// 复制并解析URL
 	if request.URL != nil {
 		// 获取原始URL字符串
@@ -209,7 +600,7 @@ func ResolveVariables(httpFile *models.HTTPFile, request *models.HTTPRequest, en
 		}
 
 		// 解析变量
-		resolvedURLStr := resolveVariablesInString(httpFile, decodedURLStr, env)
+		resolvedURLStr := r.resolve(decodedURLStr)
 
 		// 解析新的URL
 		parsedURL, err := url.Parse(resolvedURLStr)
@@ -222,19 +613,81 @@ func ResolveVariables(httpFile *models.HTTPFile, request *models.HTTPRequest, en
 	// 解析请求头中的变量
 	for name, values := range request.Headers {
 		for _, value := range values {
-			resolvedValue := resolveVariablesInString(httpFile, value, env)
+			resolvedValue := r.resolve(value)
 			resolvedReq.Headers.Add(name, resolvedValue)
 		}
 	}
 
 	// 解析请求体中的变量
-	resolvedReq.Body = resolveVariablesInString(httpFile, request.Body, env)
+	resolvedReq.Body = r.resolve(request.Body)
+
+	// 请求体文件：<@形式读取文件内容并替换变量，<形式保留文件路径在发送时流式读取
+	if request.BodyFile != "" {
+		bodyFile := r.resolve(request.BodyFile)
+		if request.BodyFileResolve {
+			data, err := os.ReadFile(bodyFile)
+			if err != nil {
+				return nil, fmt.Errorf("无法读取请求体文件: %w", err)
+			}
+			resolvedReq.Body = r.resolve(string(data))
+		} else {
+			resolvedReq.BodyFile = bodyFile
+		}
+	}
+
+	// 解析响应保存路径中的变量
+	resolvedReq.ResponseOutput = r.resolve(request.ResponseOutput)
+
+	// 解析multipart各部分中的变量
+	for _, part := range request.MultipartParts {
+		resolvedPart := &models.MultipartPart{
+			Headers: make(http.Header),
+			Body:    r.resolve(part.Body),
+		}
+		for name, values := range part.Headers {
+			for _, value := range values {
+				resolvedPart.Headers.Add(name, r.resolve(value))
+			}
+		}
+		if part.FilePath != "" {
+			filePath := r.resolve(part.FilePath)
+			if part.FileResolve {
+				data, err := os.ReadFile(filePath)
+				if err != nil {
+					return nil, fmt.Errorf("无法读取multipart文件: %w", err)
+				}
+				resolvedPart.Body = r.resolve(string(data))
+			} else {
+				resolvedPart.FilePath = filePath
+			}
+		}
+		resolvedReq.MultipartParts = append(resolvedReq.MultipartParts, resolvedPart)
+	}
+
+	// 解析断言期望值中的变量
+	for _, a := range request.Assertions {
+		resolved := *a
+		resolved.Expected = r.resolve(a.Expected)
+		resolvedReq.Assertions = append(resolvedReq.Assertions, &resolved)
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
 
 	return resolvedReq, nil
 }
 
-// resolveVariablesInString 解析字符串中的变量引用
-func resolveVariablesInString(httpFile *models.HTTPFile, input string, env string) string {
+// resolver 变量解析器，记录解析过程中遇到的第一个错误
+type resolver struct {
+	httpFile *models.HTTPFile
+	vars     map[string]string // 请求变量，优先于文件中的其他变量
+	env      string
+	err      error
+}
+
+// resolve 解析字符串中的变量引用
+func (r *resolver) resolve(input string) string {
 	if input == "" {
 		return input
 	}
@@ -242,10 +695,50 @@ func resolveVariablesInString(httpFile *models.HTTPFile, input string, env strin
 	// 使用正则表达式替换所有变量引用
 	result := variableRefRegex.ReplaceAllStringFunc(input, func(match string) string {
 		// 提取变量名
-		varName := match[2 : len(match)-2]
+		varName := strings.TrimSpace(match[2 : len(match)-2])
+
+		// 进程环境变量和.env文件中的变量
+		if name, ok := variableSourceName(varName, "$processEnv"); ok {
+			if value, found := os.LookupEnv(name); found {
+				return value
+			}
+			return match
+		}
+		if name, ok := variableSourceName(varName, "$dotenv"); ok {
+			if value, found := r.httpFile.DotEnvVars[name]; found {
+				return value
+			}
+			return match
+		}
+
+		// 引用其他命名请求的请求或响应
+		if ref, ok := parseRequestReference(r.httpFile, varName); ok {
+			value, found, err := resolveRequestReference(r.httpFile, ref)
+			if err != nil && r.err == nil {
+				r.err = err
+			}
+			if found && err == nil {
+				return value
+			}
+			return match
+		}
+
+		// 以$开头的是动态变量，每次引用都重新生成
+		if strings.HasPrefix(varName, "$") {
+			value, found, err := resolveDynamicVariable(varName)
+			if err != nil && r.err == nil {
+				r.err = err
+			}
+			if found {
+				return value
+			}
+		}
 
 		// 解析变量
-		if value, found := httpFile.ResolveVariable(varName, env); found {
+		if value, found := r.vars[varName]; found {
+			return value
+		}
+		if value, found := r.httpFile.ResolveVariable(varName, r.env); found {
 			return value
 		}
 
@@ -255,3 +748,16 @@ func resolveVariablesInString(httpFile *models.HTTPFile, input string, env strin
 
 	return result
 }
+
+// variableSourceName 解析形如"$processEnv NAME"或"$processEnv.NAME"的变量引用，返回变量名
+func variableSourceName(varName, source string) (string, bool) {
+	if !strings.HasPrefix(varName, source) {
+		return "", false
+	}
+	rest := varName[len(source):]
+	if rest == "" || (rest[0] != ' ' && rest[0] != '.') {
+		return "", false
+	}
+	name := strings.TrimSpace(rest[1:])
+	return name, name != ""
+}
@@ -0,0 +1,428 @@
+package parser
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// writeHTTPFile 在临时目录中写入.http文件并返回路径
+func writeHTTPFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.http")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		check   func(t *testing.T, dir string, file *models.HTTPFile)
+	}{
+		{
+			name: "请求名称、描述、请求头和请求体",
+			content: `### 登录
+# 使用测试账号登录
+POST http://example.com/login
+Content-Type: application/json
+
+{"user": "admin"}
+
+### 获取信息
+GET http://example.com/info
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				if len(file.Requests) != 2 {
+					t.Fatalf("请求数量 = %d, 期望 2", len(file.Requests))
+				}
+				req := file.Requests[0]
+				if req.Name != "登录" || req.Description != "使用测试账号登录" {
+					t.Errorf("名称/描述 = %q/%q", req.Name, req.Description)
+				}
+				if req.Method != "POST" || req.URL.String() != "http://example.com/login" || req.LineNumber != 3 {
+					t.Errorf("请求行 = %s %s (行 %d)", req.Method, req.URL, req.LineNumber)
+				}
+				if got := req.Headers.Get("Content-Type"); got != "application/json" {
+					t.Errorf("Content-Type = %q", got)
+				}
+				if req.Body != `{"user": "admin"}` {
+					t.Errorf("请求体 = %q", req.Body)
+				}
+				if file.Requests[1].Name != "获取信息" || file.Requests[1].LineNumber != 9 {
+					t.Errorf("第二个请求 = %q (行 %d)", file.Requests[1].Name, file.Requests[1].LineNumber)
+				}
+			},
+		},
+		{
+			name: "文件变量",
+			content: `@host = http://example.com
+
+### 查询
+GET {{host}}/users
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				if file.GlobalVars["host"] != "http://example.com" {
+					t.Errorf("变量 = %v", file.GlobalVars)
+				}
+			},
+		},
+		{
+			name: "多行响应处理脚本",
+			content: `###
+POST http://example.com/users
+
+{"name": "张三"}
+
+> {%
+    client.global.set("name", response.body.name);
+%}
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				req := file.Requests[0]
+				if req.ResponseHandler == nil || req.ResponseHandler.LineNumber != 6 ||
+					req.ResponseHandler.Content != `client.global.set("name", response.body.name);` {
+					t.Errorf("响应处理脚本 = %+v", req.ResponseHandler)
+				}
+				if req.Body != `{"name": "张三"}` {
+					t.Errorf("请求体 = %q", req.Body)
+				}
+			},
+		},
+		{
+			name: "脚本文件",
+			content: `###
+GET http://example.com/
+
+> ./handler.js
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				want := filepath.Join(dir, "handler.js")
+				if got := file.Requests[0].ResponseHandler; got == nil || got.Path != want {
+					t.Errorf("脚本 = %+v, 期望路径 %s", got, want)
+				}
+			},
+		},
+		{
+			name: "预请求脚本",
+			content: `###
+< {% request.variables.set("id", "1") %}
+GET http://example.com/users/{{id}}
+
+### 第二个
+< ./pre.js
+GET http://example.com/
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				if got := file.Requests[0].PreRequestScript; got == nil || got.Content != `request.variables.set("id", "1")` || got.LineNumber != 2 {
+					t.Errorf("预请求脚本 = %+v", got)
+				}
+				if got := file.Requests[1].PreRequestScript; got == nil || got.Path != filepath.Join(dir, "pre.js") {
+					t.Errorf("预请求脚本文件 = %+v", got)
+				}
+			},
+		},
+		{
+			name: "断言",
+			content: `###
+# @assert status == 200
+# @assert jsonpath $.name == "张  三"
+GET http://example.com/
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				assertions := file.Requests[0].Assertions
+				if len(assertions) != 2 {
+					t.Fatalf("断言数量 = %d, 期望 2", len(assertions))
+				}
+				if a := assertions[0]; a.Subject != "status" || a.Operator != "==" || a.Expected != "200" || a.LineNumber != 2 {
+					t.Errorf("第一个断言 = %+v", a)
+				}
+				if a := assertions[1]; a.Subject != "jsonpath" || a.Target != "$.name" || a.Expected != "张  三" || a.LineNumber != 3 {
+					t.Errorf("第二个断言 = %+v", a)
+				}
+			},
+		},
+		{
+			name: "请求体文件",
+			content: `###
+POST http://example.com/
+Content-Type: application/json
+
+<@ ./body.json
+
+### 第二个
+POST http://example.com/
+
+< ./raw.bin
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				req := file.Requests[0]
+				if req.BodyFile != filepath.Join(dir, "body.json") || !req.BodyFileResolve {
+					t.Errorf("请求体文件 = %q (替换变量 %v)", req.BodyFile, req.BodyFileResolve)
+				}
+				req = file.Requests[1]
+				if req.BodyFile != filepath.Join(dir, "raw.bin") || req.BodyFileResolve {
+					t.Errorf("请求体文件 = %q (替换变量 %v)", req.BodyFile, req.BodyFileResolve)
+				}
+			},
+		},
+		{
+			name: "multipart请求体",
+			content: `###
+POST http://example.com/upload
+Content-Type: multipart/form-data; boundary=WebBoundary
+
+--WebBoundary
+Content-Disposition: form-data; name="title"
+
+你好
+--WebBoundary
+Content-Disposition: form-data; name="file"; filename="a.png"
+Content-Type: image/png
+
+< ./a.png
+--WebBoundary--
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				req := file.Requests[0]
+				if req.BodyFile != "" {
+					t.Errorf("multipart部分的文件不应作为请求体文件: %q", req.BodyFile)
+				}
+				parts := req.MultipartParts
+				if len(parts) != 2 {
+					t.Fatalf("部分数量 = %d, 期望 2", len(parts))
+				}
+				if parts[0].Body != "你好" || parts[0].FilePath != "" {
+					t.Errorf("第一部分 = %q / %q", parts[0].Body, parts[0].FilePath)
+				}
+				if got := parts[1].Headers.Get("Content-Type"); got != "image/png" {
+					t.Errorf("第二部分Content-Type = %q", got)
+				}
+				if parts[1].FilePath != filepath.Join(dir, "a.png") {
+					t.Errorf("第二部分文件 = %q", parts[1].FilePath)
+				}
+			},
+		},
+		{
+			name: "保存响应",
+			content: `### 第一个
+POST http://example.com/
+
+{"a": 1}
+
+>> ./out/a.json
+
+### 第二个
+GET http://example.com/
+
+>>! ./out/b.json
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				first, second := file.Requests[0], file.Requests[1]
+				if first.Body != `{"a": 1}` {
+					t.Errorf("请求体 = %q", first.Body)
+				}
+				if first.ResponseOutput != filepath.Join(dir, "out/a.json") || first.ResponseOutputOverwrite {
+					t.Errorf("保存 = %q (覆盖 %v)", first.ResponseOutput, first.ResponseOutputOverwrite)
+				}
+				if second.ResponseOutput != filepath.Join(dir, "out/b.json") || !second.ResponseOutputOverwrite {
+					t.Errorf("保存 = %q (覆盖 %v)", second.ResponseOutput, second.ResponseOutputOverwrite)
+				}
+			},
+		},
+		{
+			name: "参考响应",
+			content: `###
+POST http://example.com/
+
+{"a": 1}
+
+<> ./out/previous.json
+<> ./out/older.json
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				req := file.Requests[0]
+				if req.Body != `{"a": 1}` {
+					t.Errorf("请求体 = %q", req.Body)
+				}
+				want := []string{filepath.Join(dir, "out/previous.json"), filepath.Join(dir, "out/older.json")}
+				if len(req.ResponseRefs) != 2 || req.ResponseRefs[0] != want[0] || req.ResponseRefs[1] != want[1] {
+					t.Errorf("参考响应 = %v, 期望 %v", req.ResponseRefs, want)
+				}
+			},
+		},
+		{
+			name: "指令",
+			content: `###
+# @name 上传
+# @no-redirect
+# @no-cookie-jar
+# @timeout 2
+# @connection-timeout 500ms
+GET http://example.com/
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				req := file.Requests[0]
+				if req.Name != "上传" {
+					t.Errorf("名称 = %q", req.Name)
+				}
+				if !req.NoRedirect || !req.NoCookieJar {
+					t.Errorf("NoRedirect/NoCookieJar = %v/%v", req.NoRedirect, req.NoCookieJar)
+				}
+				if req.Timeout != 2*time.Second || req.ConnectionTimeout != 500*time.Millisecond {
+					t.Errorf("超时 = %v/%v", req.Timeout, req.ConnectionTimeout)
+				}
+			},
+		},
+		{
+			name: "标签",
+			content: `###
+# @tag smoke, 用户 slow
+GET http://example.com/
+
+### 查询
+GET http://example.com/
+# @tag api
+`,
+			check: func(t *testing.T, dir string, file *models.HTTPFile) {
+				if got := strings.Join(file.Requests[0].TagNames(), ","); got != "slow,smoke,用户" {
+					t.Errorf("标签 = %q", got)
+				}
+				if !file.Requests[1].HasTag("api") {
+					t.Errorf("请求行之后的标签 = %v", file.Requests[1].TagNames())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeHTTPFile(t, tt.content)
+			file, err := ParseFile(path)
+			if err != nil {
+				t.Fatalf("ParseFile() 错误 = %v", err)
+			}
+			tt.check(t, filepath.Dir(path), file)
+		})
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"脚本未结束", "###\nGET http://example.com/\n\n> {%\nclient.log(1);\n", "脚本缺少结束标记"},
+		{"无效断言", "###\n# @assert\nGET http://example.com/\n", "行 2"},
+		{"空名称", "###\n# @name\nGET http://example.com/\n", "请求名称不能为空"},
+		{"无效超时", "###\n# @timeout abc\nGET http://example.com/\n", "无效的时间"},
+		{"超时为0", "###\n# @connection-timeout 0\nGET http://example.com/\n", "时间必须大于0"},
+		{"空标签", "###\n# @tag ,\nGET http://example.com/\n", "标签不能为空"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFile(writeHTTPFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFile() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveVariableSources(t *testing.T) {
+	t.Setenv("JHTTP_TEST_TOKEN", "进程变量")
+
+	httpFile := models.NewHTTPFile("test.http")
+	httpFile.DotEnvVars = map[string]string{"API_KEY": "secret", "EMPTY": ""}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"{{$processEnv JHTTP_TEST_TOKEN}}", "进程变量"},
+		{"{{$processEnv.JHTTP_TEST_TOKEN}}", "进程变量"},
+		{"{{ $processEnv JHTTP_TEST_MISSING }}", "{{ $processEnv JHTTP_TEST_MISSING }}"},
+		{"{{$dotenv API_KEY}}", "secret"},
+		{"{{$dotenv.API_KEY}}", "secret"},
+		{"a{{$dotenv EMPTY}}b", "ab"},
+		{"{{$dotenv MISSING}}", "{{$dotenv MISSING}}"},
+		{"{{$dotenvAPI_KEY}}", "{{$dotenvAPI_KEY}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			req := &models.HTTPRequest{Method: "POST", Headers: http.Header{"X-Value": []string{tt.input}}, Body: tt.input}
+			resolved, err := ResolveVariables(httpFile, req, "")
+			if err != nil {
+				t.Fatalf("ResolveVariables() 错误 = %v", err)
+			}
+			if resolved.Body != tt.want || resolved.Headers.Get("X-Value") != tt.want {
+				t.Errorf("解析结果 = %q / %q, 期望 %q", resolved.Body, resolved.Headers.Get("X-Value"), tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveBodyFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "body.json"), []byte(`{"name": "{{name}}"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	httpFile := models.NewHTTPFile(filepath.Join(dir, "test.http"))
+	httpFile.GlobalVars["name"] = "张三"
+	httpFile.GlobalVars["dir"] = dir
+
+	tests := []struct {
+		name     string
+		req      *models.HTTPRequest
+		wantBody string
+		wantFile string
+		wantErr  string
+	}{
+		{
+			name:     "替换文件中的变量",
+			req:      &models.HTTPRequest{BodyFile: "{{dir}}/body.json", BodyFileResolve: true},
+			wantBody: `{"name": "张三"}`,
+		},
+		{
+			name:     "保留文件路径在发送时读取",
+			req:      &models.HTTPRequest{BodyFile: "{{dir}}/body.json"},
+			wantFile: filepath.Join(dir, "body.json"),
+		},
+		{
+			name:    "文件不存在",
+			req:     &models.HTTPRequest{BodyFile: "{{dir}}/missing.json", BodyFileResolve: true},
+			wantErr: "无法读取请求体文件",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Method = "POST"
+			tt.req.Headers = make(http.Header)
+			resolved, err := ResolveVariables(httpFile, tt.req, "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveVariables() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveVariables() 错误 = %v", err)
+			}
+			if resolved.Body != tt.wantBody || resolved.BodyFile != tt.wantFile {
+				t.Errorf("请求体 = %q, 文件 = %q, 期望 %q, %q", resolved.Body, resolved.BodyFile, tt.wantBody, tt.wantFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/shellus/jhttp/internal/jsonpath"
+	"github.com/shellus/jhttp/internal/models"
+	"github.com/shellus/jhttp/internal/xpath"
+)
+
+// 请求引用正则表达式：请求名.(response|request).(body|headers).选择器
+var requestReferenceRegex = regexp.MustCompile(`^(.+?)\.(response|request)\.(body|headers)\.(.+)$`)
+
+// requestReference 表示对其他命名请求的请求或响应的引用，例如：
+//
+//	{{login.response.body.$.data.token}}
+//	{{login.response.body.//user/name}}
+//	{{login.response.headers.X-Token}}
+//	{{login.request.body.*}}
+type requestReference struct {
+	name     string // 请求名称
+	source   string // response或request
+	part     string // body或headers
+	selector string // JSONPath、XPath、*或请求头名称
+}
+
+// parseRequestReference 解析请求引用，只有引用的请求在文件中存在时才视为请求引用
+func parseRequestReference(httpFile *models.HTTPFile, varName string) (*requestReference, bool) {
+	matches := requestReferenceRegex.FindStringSubmatch(varName)
+	if matches == nil || httpFile.FindRequestByName(matches[1]) == nil {
+		return nil, false
+	}
+	return &requestReference{
+		name:     matches[1],
+		source:   matches[2],
+		part:     matches[3],
+		selector: strings.TrimSpace(matches[4]),
+	}, true
+}
+
+// RequestReferences 返回请求中引用的其他命名请求的名称（去重，按出现顺序）
+func RequestReferences(httpFile *models.HTTPFile, request *models.HTTPRequest) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, text := range requestTexts(request) {
+		for _, match := range variableRefRegex.FindAllStringSubmatch(text, -1) {
+			ref, ok := parseRequestReference(httpFile, strings.TrimSpace(match[1]))
+			if !ok || seen[ref.name] {
+				continue
+			}
+			seen[ref.name] = true
+			names = append(names, ref.name)
+		}
+	}
+	return names
+}
+
+// VariableReferences 返回请求中引用的普通变量名称（去重，按出现顺序）
+// 动态变量、进程环境变量、.env变量和对其他请求的引用不包含在内
+func VariableReferences(httpFile *models.HTTPFile, request *models.HTTPRequest) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, text := range requestTexts(request) {
+		for _, match := range variableRefRegex.FindAllStringSubmatch(text, -1) {
+			name := strings.TrimSpace(match[1])
+			if strings.HasPrefix(name, "$") || seen[name] {
+				continue
+			}
+			if _, ok := parseRequestReference(httpFile, name); ok {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// requestTexts 返回请求中所有可能包含变量引用的文本
+func requestTexts(request *models.HTTPRequest) []string {
+	texts := []string{request.Body, request.BodyFile, request.ResponseOutput}
+	if request.URL != nil {
+		rawURL := request.URL.String()
+		if decoded, err := url.QueryUnescape(rawURL); err == nil {
+			rawURL = decoded
+		}
+		texts = append(texts, rawURL)
+	}
+	for _, values := range request.Headers {
+		texts = append(texts, values...)
+	}
+	for _, part := range request.MultipartParts {
+		texts = append(texts, part.Body, part.FilePath)
+		for _, values := range part.Headers {
+			texts = append(texts, values...)
+		}
+	}
+	for _, a := range request.Assertions {
+		texts = append(texts, a.Expected)
+	}
+	return texts
+}
+
+// resolveRequestReference 从已执行的命名请求中取值
+// 引用的请求尚未执行时返回false，由调用方保留原样
+func resolveRequestReference(httpFile *models.HTTPFile, ref *requestReference) (string, bool, error) {
+	resp, ok := httpFile.NamedResponse(ref.name)
+	if !ok {
+		return "", false, nil
+	}
+
+	var headers map[string][]string
+	var body []byte
+	if ref.source == "response" {
+		if resp.Error != nil {
+			return "", true, fmt.Errorf("引用的请求 '%s' 执行失败: %w", ref.name, resp.Error)
+		}
+		headers, body = resp.Headers, resp.Body
+	} else {
+		if resp.Request == nil {
+			return "", false, nil
+		}
+		headers, body = resp.Request.Headers, []byte(resp.Request.Body)
+	}
+
+	if ref.part == "headers" {
+		for name, values := range headers {
+			if strings.EqualFold(name, ref.selector) && len(values) > 0 {
+				return strings.Join(values, ", "), true, nil
+			}
+		}
+		return "", true, fmt.Errorf("请求 '%s' 的%s中没有头 '%s'", ref.name, ref.source, ref.selector)
+	}
+
+	switch {
+	case ref.selector == "*":
+		return string(body), true, nil
+	case strings.HasPrefix(ref.selector, "$"):
+		value, err := jsonpath.Lookup(body, ref.selector)
+		if err != nil {
+			return "", true, fmt.Errorf("请求 '%s' 的%s体: %w", ref.name, ref.source, err)
+		}
+		return jsonpath.Stringify(value), true, nil
+	case strings.HasPrefix(ref.selector, "/"):
+		value, err := xpath.Lookup(body, ref.selector)
+		if err != nil {
+			return "", true, fmt.Errorf("请求 '%s' 的%s体: %w", ref.name, ref.source, err)
+		}
+		return value, true, nil
+	}
+	return "", true, fmt.Errorf("不支持的选择器 '%s'，请使用*、JSONPath($...)或XPath(/...)", ref.selector)
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// newReferenceFile 创建包含已执行的命名请求login、xml和broken的HTTP文件
+func newReferenceFile() *models.HTTPFile {
+	httpFile := models.NewHTTPFile("test.http")
+	login := &models.HTTPRequest{
+		Name:    "login",
+		Method:  "POST",
+		Headers: http.Header{"Content-Type": []string{"application/json"}},
+		Body:    `{"user": "admin"}`,
+	}
+	httpFile.AddRequest(login)
+	httpFile.AddRequest(&models.HTTPRequest{Name: "xml", Method: "GET", Headers: make(http.Header)})
+	httpFile.AddRequest(&models.HTTPRequest{Name: "broken", Method: "GET", Headers: make(http.Header)})
+
+	body := `{"data": {"token": "abc", "id": 1234567890123456789}}`
+	httpFile.SetNamedResponse("login", &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    http.Header{"X-Token": []string{"t1", "t2"}},
+		Body:       []byte(body),
+		BodyString: body,
+		Request:    login,
+	})
+	httpFile.SetNamedResponse("xml", &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    make(http.Header),
+		Body:       []byte(`<user><name>张三</name></user>`),
+	})
+	httpFile.SetNamedResponse("broken", &models.HTTPResponse{Error: errors.New("连接被拒绝")})
+	return httpFile
+}
+
+func TestRequestReferences(t *testing.T) {
+	httpFile := models.NewHTTPFile("test.http")
+	for _, name := range []string{"login", "profile", "upload"} {
+		httpFile.AddRequest(&models.HTTPRequest{Name: name, Method: "GET", Headers: make(http.Header)})
+	}
+
+	req := &models.HTTPRequest{
+		Method: "POST",
+		Headers: http.Header{
+			"Authorization": []string{"Bearer {{login.response.body.$.token}}"},
+			"X-Host":        []string{"{{host}}"},
+		},
+		Body: `{"id": "{{profile.response.body.$.id}}", "again": "{{ login.response.headers.X-Token }}", "other": "{{missing.response.body.$.id}}"}`,
+		MultipartParts: []*models.MultipartPart{
+			{Headers: make(http.Header), FilePath: "{{upload.request.body.*}}"},
+		},
+	}
+	req.URL, _ = url.Parse("http://example.com/{{profile.response.body.$.id}}")
+
+	// 请求头的遍历顺序不固定，排序后比较
+	got := RequestReferences(httpFile, req)
+	sort.Strings(got)
+	if want := []string{"login", "profile", "upload"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RequestReferences() = %v, 期望 %v", got, want)
+	}
+
+	// 文件中不存在的请求名称按普通变量处理
+	vars := VariableReferences(httpFile, req)
+	sort.Strings(vars)
+	if want := []string{"host", "missing.response.body.$.id"}; !reflect.DeepEqual(vars, want) {
+		t.Errorf("VariableReferences() = %v, 期望 %v", vars, want)
+	}
+}
+
+func TestResolveRequestReference(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "{{login.response.body.$.data.token}}", want: "abc"},
+		{input: "{{login.response.body.$.data.id}}", want: "1234567890123456789"},
+		{input: "{{login.response.body.*}}", want: `{"data": {"token": "abc", "id": 1234567890123456789}}`},
+		{input: "{{login.response.headers.x-token}}", want: "t1, t2"},
+		{input: "{{login.request.body.$.user}}", want: "admin"},
+		{input: "{{login.request.headers.Content-Type}}", want: "application/json"},
+		{input: "{{xml.response.body.//user/name}}", want: "张三"},
+		{input: "{{login.response.body.$.missing}}", wantErr: "路径不存在"},
+		{input: "{{login.response.headers.X-Missing}}", wantErr: "没有头 'X-Missing'"},
+		{input: "{{login.response.body.token}}", wantErr: "不支持的选择器"},
+		{input: "{{broken.response.body.$.id}}", wantErr: "引用的请求 'broken' 执行失败"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			req := &models.HTTPRequest{Method: "POST", Headers: make(http.Header), Body: tt.input}
+			resolved, err := ResolveVariables(newReferenceFile(), req, "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveVariables() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveVariables() 错误 = %v", err)
+			}
+			if resolved.Body != tt.want {
+				t.Errorf("解析结果 = %q, 期望 %q", resolved.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRequestReferenceNotExecuted(t *testing.T) {
+	httpFile := models.NewHTTPFile("test.http")
+	httpFile.AddRequest(&models.HTTPRequest{Name: "login", Method: "GET", Headers: make(http.Header)})
+
+	// 引用的请求尚未执行时保留原样
+	input := "{{login.response.body.$.token}}"
+	req := &models.HTTPRequest{Method: "POST", Headers: make(http.Header), Body: input}
+	resolved, err := ResolveVariables(httpFile, req, "")
+	if err != nil {
+		t.Fatalf("ResolveVariables() 错误 = %v", err)
+	}
+	if resolved.Body != input {
+		t.Errorf("解析结果 = %q, 期望 %q", resolved.Body, input)
+	}
+}
@@ -0,0 +1,188 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shellus/jhttp/internal/format"
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// HTML报告中响应体的最大长度，超出部分截断
+const maxHTMLBody = 256 * 1024
+
+//go:embed html.tmpl
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlTemplateText))
+
+// htmlReport HTML报告的数据
+type htmlReport struct {
+	GeneratedAt string
+	Total       int
+	Passed      int
+	Failed      int
+	Duration    string
+	Requests    []htmlRequest
+}
+
+// htmlRequest 报告中的一个请求
+type htmlRequest struct {
+	ID              int
+	File            string
+	Line            int
+	Name            string
+	Method          string
+	URL             string
+	RequestHeaders  []htmlHeader
+	RequestBody     string
+	Status          string
+	StatusClass     string
+	Time            int64
+	ResponseHeaders []htmlHeader
+	ResponseBody    string
+	OutputFile      string
+	Timing          []htmlHeader
+	Failure         string
+	Tests           []models.TestResult
+	Failed          bool
+}
+
+// htmlHeader 名称和值
+type htmlHeader struct {
+	Name  string
+	Value string
+}
+
+// WriteHTML 生成单个离线HTML文件的报告，包含每个请求的请求、响应、耗时和测试结果
+// 报告不依赖外部资源，支持折叠、搜索和只显示失败的请求
+func WriteHTML(w io.Writer, suites []Suite) error {
+	data := htmlReport{GeneratedAt: time.Now().Format("2006-01-02 15:04:05")}
+	var total time.Duration
+
+	for _, suite := range suites {
+		for _, resp := range suite.Responses {
+			req := newHTMLRequest(len(data.Requests)+1, suite, resp)
+			data.Requests = append(data.Requests, req)
+			if req.Failed {
+				data.Failed++
+			} else {
+				data.Passed++
+			}
+			total += time.Duration(resp.Time) * time.Millisecond
+		}
+	}
+	data.Total = len(data.Requests)
+	data.Duration = fmt.Sprintf("%d ms", total.Milliseconds())
+
+	return htmlTemplate.Execute(w, data)
+}
+
+// newHTMLRequest 将响应转换为报告中的请求
+func newHTMLRequest(id int, suite Suite, resp *models.HTTPResponse) htmlRequest {
+	req := resp.Request
+	item := htmlRequest{
+		ID:             id,
+		File:           suite.File,
+		Line:           req.LineNumber,
+		Name:           req.DisplayName(),
+		Method:         req.Method,
+		RequestHeaders: sortedHeaders(req.Headers),
+		Time:           resp.Time,
+		OutputFile:     resp.OutputFile,
+		Tests:          resp.Tests,
+	}
+	if req.URL != nil {
+		item.URL = req.URL.String()
+	}
+	if req.BodyFile != "" {
+		item.RequestBody = "< " + req.BodyFile
+	} else {
+		item.RequestBody = htmlBody(req.Headers.Get("Content-Type"), []byte(req.Body))
+	}
+
+	if failure := requestFailure(resp, suite.FailOnStatus); failure != nil {
+		item.Failure = failure.Message
+	}
+	item.Failed = item.Failure != "" || resp.FailedTests() > 0
+
+	if resp.Error != nil {
+		item.Status = "错误"
+		item.StatusClass = "error"
+	} else {
+		item.Status = resp.Status
+		item.StatusClass = fmt.Sprintf("s%dxx", resp.StatusCode/100)
+		item.ResponseHeaders = sortedHeaders(resp.Headers)
+		item.ResponseBody = htmlBody(resp.Headers.Get("Content-Type"), resp.Body)
+	}
+
+	if t := resp.Timing; t != nil {
+		reused := "否"
+		if t.ConnectionReused {
+			reused = "是"
+		}
+		item.Timing = []htmlHeader{
+			{"DNS解析", formatDuration(t.DNSLookup)},
+			{"TCP连接", formatDuration(t.Connect)},
+			{"TLS握手", formatDuration(t.TLSHandshake)},
+			{"服务器处理", formatDuration(t.ServerProcessing)},
+			{"首字节", formatDuration(t.TimeToFirstByte)},
+			{"内容传输", formatDuration(t.ContentTransfer)},
+			{"总耗时", formatDuration(t.Total)},
+			{"连接复用", reused},
+		}
+		if t.RemoteAddr != "" {
+			item.Timing = append(item.Timing, htmlHeader{"服务器地址", t.RemoteAddr})
+		}
+	}
+	return item
+}
+
+// htmlBody 格式化报告中的请求体或响应体，二进制内容只显示长度
+func htmlBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !utf8.Valid(body) || strings.ContainsRune(string(body), 0) {
+		return fmt.Sprintf("（二进制内容，%d 字节）", len(body))
+	}
+
+	text := format.Body(contentType, string(body), format.Options{Pretty: true})
+	if len(text) > maxHTMLBody {
+		cut := maxHTMLBody
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + fmt.Sprintf("\n...（省略 %d 字节）", len(text)-cut)
+	}
+	return text
+}
+
+// sortedHeaders 按名称排序头部，敏感头部的值被隐藏
+func sortedHeaders(headers http.Header) []htmlHeader {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]htmlHeader, 0, len(headers))
+	for _, name := range names {
+		for _, value := range headers[name] {
+			result = append(result, htmlHeader{Name: name, Value: maskHeader(name, value)})
+		}
+	}
+	return result
+}
+
+// formatDuration 以毫秒格式化耗时
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1f ms", float64(d)/float64(time.Millisecond))
+}
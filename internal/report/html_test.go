@@ -0,0 +1,54 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestNewHTMLRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		resp         *models.HTTPResponse
+		failOnStatus string
+		wantFailed   bool
+	}{
+		{"成功", newTestResponse("a", 200), "4xx,5xx", false},
+		{"匹配的状态码", newTestResponse("a", 404), "4xx,5xx", true},
+		{"不匹配的状态码", newTestResponse("a", 404), "5xx", false},
+		{"网络错误", newTestResponse("a", 0), "none", true},
+		{"测试失败", newTestResponse("a", 200, models.TestResult{Name: "t"}), "4xx,5xx", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := Suite{File: "test.http", FailOnStatus: mustPatterns(t, tt.failOnStatus)}
+			if got := newHTMLRequest(1, suite, tt.resp).Failed; got != tt.wantFailed {
+				t.Errorf("Failed = %v, 期望 %v", got, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestWriteHTMLMasksSensitiveHeaders(t *testing.T) {
+	resp := newTestResponse("登录", 200)
+	resp.Request.Headers.Set("Authorization", "Bearer secret-token")
+	resp.Request.Headers.Set("X-Request-Id", "req-1")
+	resp.Headers.Add("Set-Cookie", "session=abc; Path=/")
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, []Suite{{File: "test.http", Responses: []*models.HTTPResponse{resp}}}); err != nil {
+		t.Fatalf("WriteHTML() 错误 = %v", err)
+	}
+	html := buf.String()
+	for _, secret := range []string{"secret-token", "session=abc"} {
+		if strings.Contains(html, secret) {
+			t.Errorf("报告中包含敏感信息 %q", secret)
+		}
+	}
+	if !strings.Contains(html, "req-1") || !strings.Contains(html, "******") {
+		t.Error("报告中应保留普通头部并隐藏敏感头部的值")
+	}
+}
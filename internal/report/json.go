@@ -0,0 +1,181 @@
+package report
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shellus/jhttp/internal/executor"
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// Record 表示一个请求的执行结果，用于JSON/NDJSON输出
+type Record struct {
+	File     string          `json:"file"`
+	Name     string          `json:"name"`
+	Line     int             `json:"line"`
+	Request  RequestRecord   `json:"request"`
+	Response *ResponseRecord `json:"response,omitempty"`
+	TimeMs   int64           `json:"timeMs"`
+	Timing   *TimingRecord   `json:"timing,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Script   string          `json:"scriptError,omitempty"`
+	Tests    []TestRecord    `json:"tests"`
+	Passed   bool            `json:"passed"`
+}
+
+// RequestRecord 表示变量替换后实际发送的请求
+type RequestRecord struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Headers  http.Header `json:"headers"`
+	Body     string      `json:"body,omitempty"`
+	BodyFile string      `json:"bodyFile,omitempty"`
+}
+
+// ResponseRecord 表示收到的响应，二进制响应体使用base64编码
+type ResponseRecord struct {
+	Status       int         `json:"status"`
+	StatusText   string      `json:"statusText"`
+	Headers      http.Header `json:"headers"`
+	Body         string      `json:"body"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+	OutputFile   string      `json:"outputFile,omitempty"`
+}
+
+// TimingRecord 表示各阶段耗时，单位为毫秒
+type TimingRecord struct {
+	DNSLookup        float64 `json:"dnsLookupMs"`
+	Connect          float64 `json:"connectMs"`
+	TLSHandshake     float64 `json:"tlsHandshakeMs"`
+	ServerProcessing float64 `json:"serverProcessingMs"`
+	TimeToFirstByte  float64 `json:"timeToFirstByteMs"`
+	ContentTransfer  float64 `json:"contentTransferMs"`
+	Total            float64 `json:"totalMs"`
+	ConnectionReused bool    `json:"connectionReused"`
+	RemoteAddr       string  `json:"remoteAddr,omitempty"`
+}
+
+// TestRecord 表示一个测试或断言的结果
+type TestRecord struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewRecord 根据响应创建执行结果记录，file为请求所在的.http文件，failOnStatus为视为失败的响应状态码
+func NewRecord(file string, resp *models.HTTPResponse, failOnStatus executor.StatusPatterns) *Record {
+	req := resp.Request
+	record := &Record{
+		File:   file,
+		Name:   req.DisplayName(),
+		Line:   req.LineNumber,
+		TimeMs: resp.Time,
+		Tests:  make([]TestRecord, 0, len(resp.Tests)),
+		Passed: executor.Classify(resp, failOnStatus) == executor.FailureNone,
+	}
+
+	record.Request = RequestRecord{
+		Method:   req.Method,
+		Headers:  req.Headers,
+		Body:     req.Body,
+		BodyFile: req.BodyFile,
+	}
+	if req.URL != nil {
+		record.Request.URL = req.URL.String()
+	}
+	if record.Request.Headers == nil {
+		record.Request.Headers = http.Header{}
+	}
+
+	if resp.Error != nil {
+		record.Error = resp.Error.Error()
+	} else {
+		record.Response = &ResponseRecord{
+			Status:     resp.StatusCode,
+			StatusText: resp.Status,
+			Headers:    resp.Headers,
+			OutputFile: resp.OutputFile,
+		}
+		if utf8.Valid(resp.Body) {
+			record.Response.Body = resp.BodyString
+		} else {
+			record.Response.Body = base64.StdEncoding.EncodeToString(resp.Body)
+			record.Response.BodyEncoding = "base64"
+		}
+	}
+
+	if resp.ScriptError != nil {
+		record.Script = resp.ScriptError.Error()
+	}
+	for _, t := range resp.Tests {
+		record.Tests = append(record.Tests, TestRecord{Name: t.Name, Passed: t.Passed, Message: t.Message})
+	}
+
+	if t := resp.Timing; t != nil {
+		record.Timing = &TimingRecord{
+			DNSLookup:        milliseconds(t.DNSLookup),
+			Connect:          milliseconds(t.Connect),
+			TLSHandshake:     milliseconds(t.TLSHandshake),
+			ServerProcessing: milliseconds(t.ServerProcessing),
+			TimeToFirstByte:  milliseconds(t.TimeToFirstByte),
+			ContentTransfer:  milliseconds(t.ContentTransfer),
+			Total:            milliseconds(t.Total),
+			ConnectionReused: t.ConnectionReused,
+			RemoteAddr:       t.RemoteAddr,
+		}
+	}
+	return record
+}
+
+// milliseconds 将耗时转换为毫秒，保留三位小数
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// WriteJSON 将所有响应以JSON数组的形式写入w
+func WriteJSON(w io.Writer, suites []Suite) error {
+	records := make([]*Record, 0)
+	for _, suite := range suites {
+		for _, resp := range suite.Responses {
+			records = append(records, NewRecord(suite.File, resp, suite.FailOnStatus))
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// NDJSONWriter 每完成一个请求就输出一行JSON，并发安全
+type NDJSONWriter struct {
+	mu           sync.Mutex
+	encoder      *json.Encoder
+	failOnStatus executor.StatusPatterns
+	err          error
+}
+
+// NewNDJSONWriter 创建写入w的NDJSON输出，failOnStatus为视为失败的响应状态码
+func NewNDJSONWriter(w io.Writer, failOnStatus executor.StatusPatterns) *NDJSONWriter {
+	return &NDJSONWriter{encoder: json.NewEncoder(w), failOnStatus: failOnStatus}
+}
+
+// Write 输出一个请求的执行结果，出错后不再输出
+func (n *NDJSONWriter) Write(file string, resp *models.HTTPResponse) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return
+	}
+	n.err = n.encoder.Encode(NewRecord(file, resp, n.failOnStatus))
+}
+
+// Err 返回输出过程中遇到的第一个错误
+func (n *NDJSONWriter) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
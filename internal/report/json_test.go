@@ -0,0 +1,61 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestNewRecordPassed(t *testing.T) {
+	scriptError := newTestResponse("脚本", 200)
+	scriptError.ScriptError = errors.New("响应处理脚本: x is not defined")
+
+	tests := []struct {
+		name         string
+		resp         *models.HTTPResponse
+		failOnStatus string
+		want         bool
+	}{
+		{"成功", newTestResponse("a", 200), "4xx,5xx", true},
+		{"网络错误", newTestResponse("a", 0), "4xx,5xx", false},
+		{"匹配的状态码", newTestResponse("a", 500), "4xx,5xx", false},
+		{"不匹配的状态码", newTestResponse("a", 404), "5xx", true},
+		{"不检查状态码", newTestResponse("a", 500), "none", true},
+		{"测试失败", newTestResponse("a", 200, models.TestResult{Name: "t"}), "4xx,5xx", false},
+		{"脚本错误", scriptError, "4xx,5xx", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRecord("test.http", tt.resp, mustPatterns(t, tt.failOnStatus)).Passed; got != tt.want {
+				t.Errorf("Passed = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewNDJSONWriter(&buf, mustPatterns(t, "5xx"))
+	writer.Write("test.http", newTestResponse("不存在", 404))
+	writer.Write("test.http", newTestResponse("出错", 503))
+	if err := writer.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	decoder := json.NewDecoder(&buf)
+	var passed []bool
+	for decoder.More() {
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("解析NDJSON错误: %v", err)
+		}
+		passed = append(passed, record.Passed)
+	}
+	if len(passed) != 2 || !passed[0] || passed[1] {
+		t.Errorf("passed = %v, 期望 [true false]", passed)
+	}
+}
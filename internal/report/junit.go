@@ -0,0 +1,114 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// junitTestSuites JUnit XML的根元素
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite 对应一个.http文件
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase 对应一个请求或一个测试
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+// junitProblem 失败（failure）或错误（error）的详细信息
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Detail  string `xml:",cdata"`
+}
+
+// WriteJUnit 生成JUnit XML报告：每个.http文件是一个testsuite，每个请求和每个测试是一个testcase
+// 网络错误记为error，状态码匹配--fail-on-status、脚本错误和测试失败记为failure
+func WriteJUnit(w io.Writer, suites []Suite) error {
+	root := junitTestSuites{Name: "jhttp"}
+	var total time.Duration
+	timestamp := time.Now().Format("2006-01-02T15:04:05")
+
+	for _, suite := range suites {
+		junitSuite := junitTestSuite{Name: suite.File, Timestamp: timestamp}
+		var elapsed time.Duration
+		for _, c := range testCases(suite) {
+			junitCase := junitTestCase{Name: c.Name, ClassName: c.ClassName, Time: seconds(c.Time)}
+			if c.Failure != nil {
+				problem := &junitProblem{Message: c.Failure.Message, Type: c.Failure.Type, Detail: xmlText(c.Failure.Detail)}
+				if c.Failure.Type == failureTransport {
+					junitCase.Error = problem
+					junitSuite.Errors++
+				} else {
+					junitCase.Failure = problem
+					junitSuite.Failures++
+				}
+			}
+			junitSuite.Cases = append(junitSuite.Cases, junitCase)
+			elapsed += c.Time
+		}
+		junitSuite.Tests = len(junitSuite.Cases)
+		junitSuite.Time = seconds(elapsed)
+
+		root.Suites = append(root.Suites, junitSuite)
+		root.Tests += junitSuite.Tests
+		root.Failures += junitSuite.Failures
+		root.Errors += junitSuite.Errors
+		total += elapsed
+	}
+	root.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// xmlText 删除XML 1.0不允许的字符，如响应体中的控制字符，CDATA中出现这些字符时报告无法解析
+func xmlText(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r',
+			r >= 0x20 && r <= 0xD7FF,
+			r >= 0xE000 && r <= 0xFFFD,
+			r >= 0x10000 && r <= 0x10FFFF:
+			return r
+		}
+		return -1
+	}, text)
+}
+
+// seconds 以秒为单位格式化耗时
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
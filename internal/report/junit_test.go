@@ -0,0 +1,86 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	// 响应体中的控制字符会出现在CDATA中，报告仍应是合法的XML
+	broken := newTestResponse("出错", 500)
+	broken.Headers.Set("Content-Type", "text/plain")
+	broken.Body = []byte("错误\x01\x1b[31m]]>结束")
+	broken.BodyString = string(broken.Body)
+
+	suites := []Suite{{
+		File: "test.http",
+		Responses: []*models.HTTPResponse{
+			newTestResponse("成功", 200, models.TestResult{Name: "ok", Passed: true}, models.TestResult{Name: "bad", Message: "期望 1"}),
+			newTestResponse("不存在", 404),
+			newTestResponse("超时", 0),
+			broken,
+		},
+		FailOnStatus: mustPatterns(t, "5xx"),
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, suites); err != nil {
+		t.Fatalf("WriteJUnit() 错误 = %v", err)
+	}
+
+	var root junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &root); err != nil {
+		t.Fatalf("报告不是合法的XML: %v\n%s", err, buf.String())
+	}
+	if root.Tests != 6 || root.Failures != 2 || root.Errors != 1 {
+		t.Errorf("tests/failures/errors = %d/%d/%d, 期望 6/2/1", root.Tests, root.Failures, root.Errors)
+	}
+
+	failed := make(map[string]string)
+	for _, c := range root.Suites[0].Cases {
+		switch {
+		case c.Failure != nil:
+			failed[c.Name] = c.Failure.Type
+		case c.Error != nil:
+			failed[c.Name] = c.Error.Type
+		}
+	}
+	want := map[string]string{"成功: bad": failureTest, "超时": failureTransport, "出错": failureStatus}
+	if len(failed) != len(want) {
+		t.Errorf("失败的用例 = %v, 期望 %v", failed, want)
+	}
+	for name, kind := range want {
+		if failed[name] != kind {
+			t.Errorf("用例 %s 的失败类型 = %q, 期望 %q", name, failed[name], kind)
+		}
+	}
+
+	for _, c := range root.Suites[0].Cases {
+		if c.Name == "出错" && !strings.Contains(c.Failure.Detail, "错误[31m]]>结束") {
+			t.Errorf("摘录 = %q, 期望删除控制字符并保留其他内容", c.Failure.Detail)
+		}
+	}
+}
+
+func TestXMLText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"普通文本\t\r\n", "普通文本\t\r\n"},
+		{"a\x00b\x08c\x1fd", "abcd"},
+		{"\x7f\u0085�", "\x7f\u0085�"},
+		{"a￾b￿c", "abc"},
+		{"😀", "😀"},
+	}
+
+	for _, tt := range tests {
+		if got := xmlText(tt.input); got != tt.want {
+			t.Errorf("xmlText(%q) = %q, 期望 %q", tt.input, got, tt.want)
+		}
+	}
+}
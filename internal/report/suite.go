@@ -0,0 +1,181 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shellus/jhttp/internal/executor"
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// 报告中请求体和响应体的最大长度，超出部分截断
+const maxExcerptBody = 2048
+
+// Suite 表示一个.http文件的执行结果，在报告中作为一个测试套件
+type Suite struct {
+	File         string                  // .http文件路径
+	Responses    []*models.HTTPResponse  // 按执行顺序排列的响应
+	FailOnStatus executor.StatusPatterns // 视为失败的响应状态码，与退出码的判断一致
+}
+
+// Writer 将测试套件写入报告
+type Writer func(w io.Writer, suites []Suite) error
+
+// Writers 支持的报告格式
+var Writers = map[string]Writer{
+	"junit": WriteJUnit,
+	"tap":   WriteTAP,
+	"html":  WriteHTML,
+}
+
+// 失败类型
+const (
+	failureTransport = "transport" // 网络错误等请求没有完成的情况
+	failureStatus    = "status"    // 响应状态码匹配--fail-on-status
+	failureScript    = "script"    // 响应处理脚本出错
+	failureTest      = "test"      // 测试或断言失败
+)
+
+// testCase 报告中的一个测试用例：每个请求是一个用例，请求中的每个测试和断言也各是一个用例
+type testCase struct {
+	Name      string
+	ClassName string
+	Time      time.Duration
+	Failure   *testFailure
+}
+
+// testFailure 测试用例失败的原因
+type testFailure struct {
+	Type    string
+	Message string
+	Detail  string // 请求和响应摘录
+}
+
+// testCases 将套件中的响应转换为测试用例
+func testCases(suite Suite) []testCase {
+	cases := make([]testCase, 0)
+	for _, resp := range suite.Responses {
+		name := resp.Request.DisplayName()
+		elapsed := time.Duration(resp.Time) * time.Millisecond
+
+		requestCase := testCase{Name: name, ClassName: suite.File, Time: elapsed, Failure: requestFailure(resp, suite.FailOnStatus)}
+		if requestCase.Failure != nil {
+			requestCase.Failure.Detail = excerpt(resp)
+		}
+		cases = append(cases, requestCase)
+
+		for _, t := range resp.Tests {
+			testCase := testCase{Name: name + ": " + t.Name, ClassName: suite.File + "." + name}
+			if !t.Passed {
+				testCase.Failure = &testFailure{Type: failureTest, Message: t.Message, Detail: excerpt(resp)}
+			}
+			cases = append(cases, testCase)
+		}
+	}
+	return cases
+}
+
+// requestFailure 返回请求本身的失败原因（不包括测试），没有失败时返回nil
+func requestFailure(resp *models.HTTPResponse, failOnStatus executor.StatusPatterns) *testFailure {
+	switch {
+	case resp.Error != nil:
+		return &testFailure{Type: failureTransport, Message: resp.Error.Error()}
+	case executor.Classify(resp, failOnStatus) == executor.FailureStatus:
+		return &testFailure{Type: failureStatus, Message: fmt.Sprintf("响应状态: %s", resp.Status)}
+	case resp.ScriptError != nil:
+		return &testFailure{Type: failureScript, Message: resp.ScriptError.Error()}
+	}
+	return nil
+}
+
+// excerpt 生成失败用例中的请求和响应摘录
+func excerpt(resp *models.HTTPResponse) string {
+	var b strings.Builder
+	req := resp.Request
+	if req.URL != nil {
+		fmt.Fprintf(&b, "%s %s\n", req.Method, req.URL.String())
+	}
+	writeHeaders(&b, req.Headers)
+	if req.BodyFile != "" {
+		fmt.Fprintf(&b, "\n< %s\n", req.BodyFile)
+	} else if req.Body != "" {
+		fmt.Fprintf(&b, "\n%s\n", truncate(req.Body))
+	}
+
+	b.WriteString("\n")
+	if resp.Error != nil {
+		fmt.Fprintf(&b, "错误: %v\n", resp.Error)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "HTTP/1.1 %s\n", resp.Status)
+	writeHeaders(&b, resp.Headers)
+	switch {
+	case len(resp.Body) == 0:
+	case !utf8.Valid(resp.Body) || strings.ContainsRune(resp.BodyString, 0):
+		fmt.Fprintf(&b, "\n（二进制内容，%d 字节）\n", len(resp.Body))
+	default:
+		fmt.Fprintf(&b, "\n%s\n", truncate(resp.BodyString))
+	}
+	return b.String()
+}
+
+// writeHeaders 按名称排序输出头部，敏感头部的值被隐藏
+func writeHeaders(b *strings.Builder, headers map[string][]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			fmt.Fprintf(b, "%s: %s\n", name, maskHeader(name, value))
+		}
+	}
+}
+
+// 值需要在报告中隐藏的头部
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+	"api-key":             true,
+	"x-auth-token":        true,
+	"x-access-token":      true,
+	"x-csrf-token":        true,
+	"x-xsrf-token":        true,
+}
+
+// 名称中包含这些词的头部也视为敏感头部，如X-Goog-Api-Key、X-Client-Secret
+var sensitiveHeaderWords = []string{"token", "secret", "password", "api-key", "apikey", "session"}
+
+// maskHeader 隐藏报告中敏感头部的值，避免报告作为CI产物泄露凭据
+func maskHeader(name, value string) string {
+	lower := strings.ToLower(name)
+	sensitive := sensitiveHeaders[lower]
+	for _, word := range sensitiveHeaderWords {
+		sensitive = sensitive || strings.Contains(lower, word)
+	}
+	if !sensitive || value == "" {
+		return value
+	}
+	return "******"
+}
+
+// truncate 截断过长的请求体或响应体
+func truncate(body string) string {
+	if len(body) <= maxExcerptBody {
+		return body
+	}
+	cut := maxExcerptBody
+	// 避免截断在多字节字符中间
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return body[:cut] + fmt.Sprintf("\n...（省略 %d 字节）", len(body)-cut)
+}
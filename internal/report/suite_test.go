@@ -0,0 +1,141 @@
+package report
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/executor"
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// newTestResponse 创建测试用的响应，status为0时表示网络错误
+func newTestResponse(name string, status int, tests ...models.TestResult) *models.HTTPResponse {
+	req := &models.HTTPRequest{Name: name, Method: "GET", Headers: make(http.Header)}
+	req.URL, _ = url.Parse("http://example.com/" + name)
+	resp := &models.HTTPResponse{Request: req, StatusCode: status, Status: http.StatusText(status), Headers: make(http.Header), Tests: tests}
+	if status == 0 {
+		resp.Error = errors.New("connection refused")
+	}
+	return resp
+}
+
+// mustPatterns 解析--fail-on-status
+func mustPatterns(t *testing.T, spec string) executor.StatusPatterns {
+	t.Helper()
+	patterns, err := executor.ParseStatusPatterns(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return patterns
+}
+
+func TestRequestFailure(t *testing.T) {
+	scriptError := newTestResponse("脚本", 200)
+	scriptError.ScriptError = errors.New("响应处理脚本: x is not defined")
+
+	tests := []struct {
+		name         string
+		resp         *models.HTTPResponse
+		failOnStatus string
+		want         string // 失败类型，为空表示没有失败
+	}{
+		{"成功", newTestResponse("a", 200), "4xx,5xx", ""},
+		{"网络错误", newTestResponse("a", 0), "none", failureTransport},
+		{"匹配的状态码", newTestResponse("a", 404), "4xx,5xx", failureStatus},
+		{"不匹配的状态码", newTestResponse("a", 404), "5xx", ""},
+		{"不检查状态码", newTestResponse("a", 500), "none", ""},
+		{"匹配的非错误状态码", newTestResponse("a", 302), "3xx", failureStatus},
+		{"脚本错误", scriptError, "4xx,5xx", failureScript},
+		{"测试失败不算请求失败", newTestResponse("a", 200, models.TestResult{Name: "t"}), "4xx,5xx", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := requestFailure(tt.resp, mustPatterns(t, tt.failOnStatus))
+			got := ""
+			if failure != nil {
+				got = failure.Type
+			}
+			if got != tt.want {
+				t.Errorf("requestFailure() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestCasesFailOnStatus(t *testing.T) {
+	// 状态码不匹配--fail-on-status时用例通过，报告与退出码的判断一致
+	suite := Suite{
+		File: "test.http",
+		Responses: []*models.HTTPResponse{
+			newTestResponse("不存在", 404, models.TestResult{Name: "status == 404", Passed: true}),
+			newTestResponse("出错", 503),
+		},
+		FailOnStatus: mustPatterns(t, "5xx"),
+	}
+
+	var failed []string
+	for _, c := range testCases(suite) {
+		if c.Failure != nil {
+			failed = append(failed, c.Name+" "+c.Failure.Type)
+		}
+	}
+	if len(failed) != 1 || failed[0] != "出错 status" {
+		t.Errorf("失败的用例 = %v, 期望 [出错 status]", failed)
+	}
+
+	worst := executor.WorstFailure(suite.Responses, suite.FailOnStatus)
+	if worst != executor.FailureStatus {
+		t.Errorf("WorstFailure() = %v, 期望 FailureStatus", worst)
+	}
+}
+
+func TestExcerptMasksSensitiveHeaders(t *testing.T) {
+	resp := newTestResponse("登录", 401)
+	resp.Request.Headers.Set("Authorization", "Bearer secret-token")
+	resp.Request.Headers.Set("Cookie", "session=abc")
+	resp.Request.Headers.Set("X-Api-Key", "key-123")
+	resp.Request.Headers.Set("X-Goog-Api-Key", "key-456")
+	resp.Request.Headers.Set("Proxy-Authorization", "Basic dXNlcjpwYXNz")
+	resp.Request.Headers.Set("Accept", "application/json")
+	resp.Headers.Add("Set-Cookie", "session=def; Path=/")
+	resp.Headers.Set("Content-Type", "application/json")
+
+	text := excerpt(resp)
+	for _, secret := range []string{"secret-token", "session=abc", "key-123", "key-456", "dXNlcjpwYXNz", "session=def"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("摘录中包含敏感信息 %q:\n%s", secret, text)
+		}
+	}
+	for _, line := range []string{"Authorization: ******", "Set-Cookie: ******", "Accept: application/json", "Content-Type: application/json"} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("摘录中没有 %q:\n%s", line, text)
+		}
+	}
+}
+
+func TestMaskHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"Authorization", "Bearer abc", "******"},
+		{"authorization", "Bearer abc", "******"},
+		{"X-Auth-Token", "abc", "******"},
+		{"X-Client-Secret", "abc", "******"},
+		{"X-Session-Id", "abc", "******"},
+		{"Cookie", "", ""},
+		{"Content-Type", "text/plain", "text/plain"},
+		{"X-Request-Id", "abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		if got := maskHeader(tt.name, tt.value); got != tt.want {
+			t.Errorf("maskHeader(%q, %q) = %q, 期望 %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
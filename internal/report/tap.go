@@ -0,0 +1,49 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteTAP 生成TAP（Test Anything Protocol）version 13报告，每个请求和每个测试是一个测试点
+// 失败的测试点附带YAML块，包含失败类型、原因以及请求和响应摘录
+func WriteTAP(w io.Writer, suites []Suite) error {
+	out := bufio.NewWriter(w)
+
+	cases := make([]testCase, 0)
+	for _, suite := range suites {
+		cases = append(cases, testCases(suite)...)
+	}
+
+	fmt.Fprintln(out, "TAP version 13")
+	fmt.Fprintf(out, "1..%d\n", len(cases))
+	for i, c := range cases {
+		description := tapEscape(c.ClassName + ": " + c.Name)
+		if c.Failure == nil {
+			fmt.Fprintf(out, "ok %d - %s\n", i+1, description)
+			continue
+		}
+
+		fmt.Fprintf(out, "not ok %d - %s\n", i+1, description)
+		fmt.Fprintln(out, "  ---")
+		fmt.Fprintf(out, "  type: %s\n", c.Failure.Type)
+		fmt.Fprintf(out, "  message: %q\n", c.Failure.Message)
+		fmt.Fprintf(out, "  duration_ms: %d\n", c.Time.Milliseconds())
+		if c.Failure.Detail != "" {
+			fmt.Fprintln(out, "  detail: |")
+			for _, line := range strings.Split(strings.TrimRight(c.Failure.Detail, "\n"), "\n") {
+				fmt.Fprintf(out, "    %s\n", line)
+			}
+		}
+		fmt.Fprintln(out, "  ...")
+	}
+	return out.Flush()
+}
+
+// tapEscape 转义测试点描述中的#（TAP指令）和换行
+func tapEscape(text string) string {
+	text = strings.ReplaceAll(text, "#", "\\#")
+	return strings.ReplaceAll(text, "\n", " ")
+}
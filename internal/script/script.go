@@ -0,0 +1,295 @@
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/dop251/goja"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// client.log的输出位置，默认为标准输出
+var (
+	logMu     sync.Mutex
+	logOutput io.Writer = os.Stdout
+)
+
+// SetLogOutput 设置client.log的输出位置
+// 标准输出用于JSON、NDJSON或报告等机器可读的结果时，应将日志输出到标准错误，避免破坏输出格式
+func SetLogOutput(w io.Writer) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logOutput = w
+}
+
+// LoadSource 读取脚本源码，外部脚本从文件读取，内联脚本直接返回内容
+func LoadSource(s *models.Script) (string, error) {
+	if s.Path == "" {
+		return s.Content, nil
+	}
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return "", fmt.Errorf("无法读取脚本文件: %w", err)
+	}
+	return string(data), nil
+}
+
+// RunPreRequest 执行预请求脚本
+// 脚本中可以使用client和request对象，通过request.variables.set设置的变量写入request.Variables
+func RunPreRequest(httpFile *models.HTTPFile, s *models.Script, request *models.HTTPRequest, env string) error {
+	vm := goja.New()
+	vm.Set("client", newClient(vm, httpFile))
+	vm.Set("request", newRequest(vm, httpFile, request, env))
+	return run(vm, s)
+}
+
+// RunResponseHandler 执行响应处理脚本
+// 脚本中可以使用IntelliJ HTTP Client的client和response对象
+func RunResponseHandler(httpFile *models.HTTPFile, s *models.Script, resp *models.HTTPResponse) error {
+	vm := goja.New()
+	client := newClient(vm, httpFile)
+	addTestFunctions(vm, client, resp)
+	vm.Set("client", client)
+	vm.Set("response", newResponse(vm, resp))
+	return run(vm, s)
+}
+
+// run 加载并执行脚本
+func run(vm *goja.Runtime, s *models.Script) error {
+	source, err := LoadSource(s)
+	if err != nil {
+		return err
+	}
+
+	if _, err := vm.RunScript(scriptName(s), source); err != nil {
+		return fmt.Errorf("执行脚本失败: %w", err)
+	}
+	return nil
+}
+
+// scriptName 返回脚本名称，用于错误信息中定位脚本
+func scriptName(s *models.Script) string {
+	if s.Path != "" {
+		return s.Path
+	}
+	return fmt.Sprintf("内联脚本(行 %d)", s.LineNumber)
+}
+
+// newClient 创建client对象
+func newClient(vm *goja.Runtime, httpFile *models.HTTPFile) *goja.Object {
+	client := vm.NewObject()
+	client.Set("global", newGlobal(vm, httpFile))
+	client.Set("log", func(call goja.FunctionCall) goja.Value {
+		parts := make([]string, 0, len(call.Arguments))
+		for _, arg := range call.Arguments {
+			parts = append(parts, valueToString(arg))
+		}
+		// 并行执行时多个脚本可能同时输出，加锁避免日志行交错
+		logMu.Lock()
+		fmt.Fprintln(logOutput, strings.Join(parts, " "))
+		logMu.Unlock()
+		return goja.Undefined()
+	})
+	return client
+}
+
+// addTestFunctions 为client对象添加test和assert方法，结果记录到响应中
+// client.assert在client.test内失败时抛出异常使该测试失败，在测试外则直接记录为一个断言结果
+func addTestFunctions(vm *goja.Runtime, client *goja.Object, resp *models.HTTPResponse) {
+	inTest := false
+
+	client.Set("test", func(name string, fn goja.Callable) {
+		inTest = true
+		_, err := fn(goja.Undefined())
+		inTest = false
+
+		if err != nil {
+			resp.AddTestResult(name, false, exceptionMessage(err))
+			return
+		}
+		resp.AddTestResult(name, true, "")
+	})
+
+	client.Set("assert", func(call goja.FunctionCall) goja.Value {
+		passed := call.Argument(0).ToBoolean()
+		message := "断言失败"
+		if msg := call.Argument(1); !goja.IsUndefined(msg) {
+			message = msg.String()
+		}
+
+		if inTest {
+			if !passed {
+				errObj, _ := vm.New(vm.Get("Error"), vm.ToValue(message))
+				panic(errObj)
+			}
+			return goja.Undefined()
+		}
+
+		if passed {
+			resp.AddTestResult(message, true, "")
+		} else {
+			resp.AddTestResult(message, false, message)
+		}
+		return goja.Undefined()
+	})
+}
+
+// exceptionMessage 提取脚本异常的错误信息
+func exceptionMessage(err error) string {
+	if ex, ok := err.(*goja.Exception); ok {
+		if obj, ok := ex.Value().(*goja.Object); ok {
+			if msg := obj.Get("message"); msg != nil && !goja.IsUndefined(msg) {
+				return msg.String()
+			}
+		}
+		return ex.Value().String()
+	}
+	return err.Error()
+}
+
+// newGlobal 创建client.global对象，变量保存在HTTP文件的运行时变量中
+func newGlobal(vm *goja.Runtime, httpFile *models.HTTPFile) *goja.Object {
+	global := vm.NewObject()
+	global.Set("set", func(name string, value goja.Value) {
+		httpFile.SetRuntimeVar(name, valueToString(value))
+	})
+	global.Set("get", func(name string) goja.Value {
+		if val, ok := httpFile.RuntimeVar(name); ok {
+			return vm.ToValue(val)
+		}
+		return goja.Null()
+	})
+	global.Set("isEmpty", func() bool {
+		return !httpFile.HasRuntimeVars()
+	})
+	global.Set("clear", func(name string) {
+		httpFile.DeleteRuntimeVar(name)
+	})
+	global.Set("clearAll", func() {
+		httpFile.ClearRuntimeVars()
+	})
+	return global
+}
+
+// newRequest 创建预请求脚本中的request对象
+func newRequest(vm *goja.Runtime, httpFile *models.HTTPFile, request *models.HTTPRequest, env string) *goja.Object {
+	if request.Variables == nil {
+		request.Variables = make(map[string]string)
+	}
+
+	obj := vm.NewObject()
+	obj.Set("method", request.Method)
+
+	variables := vm.NewObject()
+	variables.Set("set", func(name string, value goja.Value) {
+		request.Variables[name] = valueToString(value)
+	})
+	variables.Set("get", func(name string) goja.Value {
+		if val, ok := request.Variables[name]; ok {
+			return vm.ToValue(val)
+		}
+		return goja.Null()
+	})
+	obj.Set("variables", variables)
+
+	environment := vm.NewObject()
+	environment.Set("get", func(name string) goja.Value {
+		if val, ok := httpFile.EnvironmentVars[env][name]; ok {
+			return vm.ToValue(val)
+		}
+		return goja.Null()
+	})
+	obj.Set("environment", environment)
+
+	rawURL := ""
+	if request.URL != nil {
+		rawURL = request.URL.String()
+	}
+	urlObj := vm.NewObject()
+	urlObj.Set("getRaw", func() string { return rawURL })
+	obj.Set("url", urlObj)
+
+	body := vm.NewObject()
+	body.Set("getRaw", func() string { return request.Body })
+	obj.Set("body", body)
+
+	headers := vm.NewObject()
+	headers.Set("findByName", func(name string) goja.Value {
+		value := request.Headers.Get(name)
+		if value == "" {
+			return goja.Null()
+		}
+		header := vm.NewObject()
+		header.Set("name", name)
+		header.Set("getRawValue", func() string { return value })
+		return header
+	})
+	obj.Set("headers", headers)
+
+	return obj
+}
+
+// newResponse 创建response对象
+func newResponse(vm *goja.Runtime, resp *models.HTTPResponse) *goja.Object {
+	response := vm.NewObject()
+	response.Set("status", resp.StatusCode)
+	response.Set("body", responseBody(vm, resp))
+
+	headers := vm.NewObject()
+	headers.Set("valueOf", func(name string) goja.Value {
+		if values := resp.Headers.Values(name); len(values) > 0 {
+			return vm.ToValue(values[0])
+		}
+		return goja.Null()
+	})
+	headers.Set("valuesOf", func(name string) []string {
+		values := resp.Headers.Values(name)
+		if values == nil {
+			return []string{}
+		}
+		return values
+	})
+	response.Set("headers", headers)
+
+	mimeType, params, _ := mime.ParseMediaType(resp.Headers.Get("Content-Type"))
+	contentType := vm.NewObject()
+	contentType.Set("mimeType", mimeType)
+	contentType.Set("charset", params["charset"])
+	response.Set("contentType", contentType)
+
+	return response
+}
+
+// responseBody 返回脚本中的response.body
+// JSON响应使用脚本引擎的JSON.parse解析为对象，与IntelliJ的行为一致，其他类型的响应保持字符串形式
+func responseBody(vm *goja.Runtime, resp *models.HTTPResponse) goja.Value {
+	if strings.Contains(resp.Headers.Get("Content-Type"), "json") {
+		if parse, ok := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("parse")); ok {
+			if data, err := parse(goja.Undefined(), vm.ToValue(resp.BodyString)); err == nil {
+				return data
+			}
+		}
+	}
+	return vm.ToValue(resp.BodyString)
+}
+
+// valueToString 将脚本中的值转换为字符串，对象和数组会被序列化为JSON
+func valueToString(value goja.Value) string {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return ""
+	}
+	switch value.Export().(type) {
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(value.Export()); err == nil {
+			return string(data)
+		}
+	}
+	return value.String()
+}
@@ -0,0 +1,302 @@
+package script
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shellus/jhttp/internal/models"
+)
+
+// newTestResponse 创建用于测试的响应
+func newTestResponse(status int, contentType, body string) *models.HTTPResponse {
+	headers := make(http.Header)
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	headers.Add("Set-Cookie", "a=1")
+	headers.Add("Set-Cookie", "b=2")
+	return &models.HTTPResponse{StatusCode: status, Headers: headers, Body: []byte(body), BodyString: body}
+}
+
+func TestRunResponseHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		script      string
+		want        map[string]string // 脚本执行后的运行时变量
+	}{
+		{
+			name:        "JSON响应体",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"token": "abc", "user": {"id": 7, "roles": ["admin"]}}`,
+			script: `client.global.set("token", response.body.token);
+client.global.set("id", response.body.user.id);
+client.global.set("roles", response.body.user.roles);
+client.global.set("user", response.body.user);`,
+			want: map[string]string{"token": "abc", "id": "7", "roles": `["admin"]`, "user": `{"id":7,"roles":["admin"]}`},
+		},
+		{
+			name:        "非JSON响应体保持字符串",
+			contentType: "text/plain",
+			body:        `{"token": "abc"}`,
+			script:      `client.global.set("type", typeof response.body); client.global.set("body", response.body);`,
+			want:        map[string]string{"type": "string", "body": `{"token": "abc"}`},
+		},
+		{
+			name:        "无效的JSON保持字符串",
+			contentType: "application/json",
+			body:        `{"token": `,
+			script:      `client.global.set("type", typeof response.body);`,
+			want:        map[string]string{"type": "string"},
+		},
+		{
+			name:        "JSON字符串中的特殊字符",
+			contentType: "application/json",
+			body:        `{"text": "第一行\n\"引号\"", "proto": {"__proto__": 1}}`,
+			script:      `client.global.set("text", response.body.text);`,
+			want:        map[string]string{"text": "第一行\n\"引号\""},
+		},
+		{
+			name:        "状态码、响应头和内容类型",
+			contentType: "application/json; charset=utf-8",
+			body:        `{}`,
+			script: `client.global.set("status", response.status);
+client.global.set("cookie", response.headers.valueOf("set-cookie"));
+client.global.set("cookies", response.headers.valuesOf("Set-Cookie").join(";"));
+client.global.set("missing", response.headers.valueOf("X-Missing") === null);
+client.global.set("none", response.headers.valuesOf("X-Missing").length);
+client.global.set("mime", response.contentType.mimeType);
+client.global.set("charset", response.contentType.charset);`,
+			want: map[string]string{
+				"status": "201", "cookie": "a=1", "cookies": "a=1;b=2", "missing": "true", "none": "0",
+				"mime": "application/json", "charset": "utf-8",
+			},
+		},
+		{
+			name:   "client.global的get、clear和isEmpty",
+			script: `client.global.set("a", "1"); client.global.set("b", client.global.get("a") + "2"); client.global.clear("a"); client.global.set("empty", client.global.isEmpty()); client.global.set("missing", client.global.get("a") === null);`,
+			want:   map[string]string{"b": "12", "empty": "false", "missing": "true"},
+		},
+		{
+			name:   "client.global.clearAll",
+			script: `client.global.set("a", "1"); client.global.clearAll(); client.global.set("empty", String(client.global.isEmpty()));`,
+			want:   map[string]string{"empty": "true"},
+		},
+		{
+			name:   "null和undefined保存为空字符串",
+			script: `client.global.set("a", null); client.global.set("b", undefined);`,
+			want:   map[string]string{"a": "", "b": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpFile := models.NewHTTPFile("test.http")
+			resp := newTestResponse(201, tt.contentType, tt.body)
+			if err := RunResponseHandler(httpFile, &models.Script{Content: tt.script, LineNumber: 3}, resp); err != nil {
+				t.Fatalf("RunResponseHandler() 错误 = %v", err)
+			}
+			for name, want := range tt.want {
+				if got, _ := httpFile.RuntimeVar(name); got != want {
+					t.Errorf("%s = %q, 期望 %q", name, got, want)
+				}
+			}
+			if len(httpFile.RuntimeVars) != len(tt.want) {
+				t.Errorf("运行时变量 = %v, 期望 %v", httpFile.RuntimeVars, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunResponseHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  *models.Script
+		wantErr string
+	}{
+		{"语法错误", &models.Script{Content: "client.global.set(", LineNumber: 12}, "内联脚本(行 12)"},
+		{"运行时异常", &models.Script{Content: `throw new Error("出错了")`, LineNumber: 3}, "出错了"},
+		{"脚本文件不存在", &models.Script{Path: filepath.Join(t.TempDir(), "missing.js")}, "无法读取脚本文件"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RunResponseHandler(models.NewHTTPFile("test.http"), tt.script, newTestResponse(200, "", ""))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RunResponseHandler() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunResponseHandlerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handler.js")
+	if err := os.WriteFile(path, []byte(`client.global.set("status", response.status);`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	httpFile := models.NewHTTPFile("test.http")
+	if err := RunResponseHandler(httpFile, &models.Script{Path: path}, newTestResponse(404, "", "")); err != nil {
+		t.Fatalf("RunResponseHandler() 错误 = %v", err)
+	}
+	if got, _ := httpFile.RuntimeVar("status"); got != "404" {
+		t.Errorf("status = %q, 期望 404", got)
+	}
+}
+
+func TestRunPreRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		noVars bool              // 请求还没有变量
+		want   map[string]string // 脚本执行后的请求变量
+	}{
+		{
+			name:   "设置请求变量",
+			script: `request.variables.set("id", 42); request.variables.set("ids", [1, 2]);`,
+			noVars: true,
+			want:   map[string]string{"id": "42", "ids": "[1,2]"},
+		},
+		{
+			name:   "读取已有的请求变量",
+			script: `request.variables.set("copy", request.variables.get("existing") + "!"); request.variables.set("missing", request.variables.get("none") === null);`,
+			want:   map[string]string{"existing": "原值", "copy": "原值!", "missing": "true"},
+		},
+		{
+			name:   "读取环境变量",
+			script: `request.variables.set("host", request.environment.get("host")); request.variables.set("missing", request.environment.get("none") === null);`,
+			want:   map[string]string{"existing": "原值", "host": "dev.example.com", "missing": "true"},
+		},
+		{
+			name: "读取请求内容",
+			script: `request.variables.set("method", request.method);
+request.variables.set("url", request.url.getRaw());
+request.variables.set("body", request.body.getRaw());
+request.variables.set("type", request.headers.findByName("content-type").getRawValue());
+request.variables.set("missing", request.headers.findByName("X-Missing") === null);`,
+			want: map[string]string{
+				"existing": "原值", "method": "POST", "url": "http://example.com/users?page=2", "body": `{"name": "{{name}}"}`,
+				"type": "application/json", "missing": "true",
+			},
+		},
+		{
+			name:   "client.global",
+			script: `client.global.set("token", "abc"); request.variables.set("token", client.global.get("token"));`,
+			want:   map[string]string{"existing": "原值", "token": "abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpFile := models.NewHTTPFile("test.http")
+			httpFile.EnvironmentVars["dev"] = map[string]string{"host": "dev.example.com"}
+			req := &models.HTTPRequest{
+				Method:    "POST",
+				Headers:   http.Header{"Content-Type": []string{"application/json"}},
+				Body:      `{"name": "{{name}}"}`,
+				Variables: map[string]string{"existing": "原值"},
+			}
+			req.URL, _ = url.Parse("http://example.com/users?page=2")
+			if tt.noVars {
+				req.Variables = nil
+			}
+
+			if err := RunPreRequest(httpFile, &models.Script{Content: tt.script, LineNumber: 2}, req, "dev"); err != nil {
+				t.Fatalf("RunPreRequest() 错误 = %v", err)
+			}
+			if !reflect.DeepEqual(req.Variables, tt.want) {
+				t.Errorf("请求变量 = %v, 期望 %v", req.Variables, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPreRequestErrors(t *testing.T) {
+	req := &models.HTTPRequest{Method: "GET", Headers: make(http.Header)}
+	err := RunPreRequest(models.NewHTTPFile("test.http"), &models.Script{Content: "response.status", LineNumber: 5}, req, "")
+	if err == nil || !strings.Contains(err.Error(), "response is not defined") {
+		t.Errorf("RunPreRequest() 错误 = %v, 预请求脚本中没有response对象", err)
+	}
+}
+
+func TestClientTest(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []models.TestResult
+	}{
+		{
+			name:   "通过的测试",
+			script: `client.test("状态码为200", function() { client.assert(response.status === 200, "状态码错误"); });`,
+			want:   []models.TestResult{{Name: "状态码为200", Passed: true}},
+		},
+		{
+			name:   "失败的断言",
+			script: `client.test("状态码为201", function() { client.assert(response.status === 201, "期望201"); client.global.set("after", "1"); });`,
+			want:   []models.TestResult{{Name: "状态码为201", Message: "期望201"}},
+		},
+		{
+			name:   "没有消息的断言",
+			script: `client.test("检查", function() { client.assert(false); });`,
+			want:   []models.TestResult{{Name: "检查", Message: "断言失败"}},
+		},
+		{
+			name:   "测试中的异常",
+			script: `client.test("异常", function() { response.body.missing.field; });`,
+			want:   []models.TestResult{{Name: "异常", Message: "Cannot read property 'field' of undefined"}},
+		},
+		{
+			name:   "抛出字符串",
+			script: `client.test("字符串", function() { throw "坏了"; });`,
+			want:   []models.TestResult{{Name: "字符串", Message: "坏了"}},
+		},
+		{
+			name:   "测试之外的断言",
+			script: `client.assert(true, "有响应体"); client.assert(response.body.ok, "ok为true");`,
+			want:   []models.TestResult{{Name: "有响应体", Passed: true}, {Name: "ok为true", Message: "ok为true"}},
+		},
+		{
+			name: "多个测试互不影响",
+			script: `client.test("第一个", function() { client.assert(false, "失败"); });
+client.test("第二个", function() { client.assert(true); });`,
+			want: []models.TestResult{{Name: "第一个", Message: "失败"}, {Name: "第二个", Passed: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpFile := models.NewHTTPFile("test.http")
+			resp := newTestResponse(200, "application/json", `{"ok": false}`)
+			if err := RunResponseHandler(httpFile, &models.Script{Content: tt.script}, resp); err != nil {
+				t.Fatalf("RunResponseHandler() 错误 = %v", err)
+			}
+			if !reflect.DeepEqual(resp.Tests, tt.want) {
+				t.Errorf("测试结果 = %+v, 期望 %+v", resp.Tests, tt.want)
+			}
+			if _, ok := httpFile.RuntimeVar("after"); ok {
+				t.Error("断言失败后测试函数应该停止执行")
+			}
+		})
+	}
+}
+
+func TestClientLog(t *testing.T) {
+	var buf strings.Builder
+	SetLogOutput(&buf)
+	t.Cleanup(func() { SetLogOutput(os.Stdout) })
+
+	script := `client.log("状态码", response.status, {"ok": true}, [1, 2], null);
+client.log();`
+	if err := RunResponseHandler(models.NewHTTPFile("test.http"), &models.Script{Content: script}, newTestResponse(200, "", "")); err != nil {
+		t.Fatalf("RunResponseHandler() 错误 = %v", err)
+	}
+	if want := "状态码 200 {\"ok\":true} [1,2] \n\n"; buf.String() != want {
+		t.Errorf("日志 = %q, 期望 %q", buf.String(), want)
+	}
+}
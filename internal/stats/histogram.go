@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// 直方图按微秒记录，每个2的幂区间再细分为subBucketCount个桶，相对误差约为3%
+const (
+	subBucketBits  = 6
+	subBucketCount = 1 << subBucketBits
+	subBucketHalf  = subBucketCount / 2
+)
+
+// Histogram 记录耗时分布的直方图，占用内存与样本数量无关
+// Histogram不是并发安全的，多个协程同时记录时需要调用方加锁
+type Histogram struct {
+	counts []int64
+	total  int64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+// NewHistogram 创建一个空的直方图
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// Record 记录一个耗时
+func (h *Histogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	index := bucketIndex(uint64(d / time.Microsecond))
+	if index >= len(h.counts) {
+		counts := make([]int64, index+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[index]++
+
+	if h.total == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.total++
+	h.sum += d
+}
+
+// Merge 将另一个直方图的数据合并到当前直方图
+func (h *Histogram) Merge(other *Histogram) {
+	if other.total == 0 {
+		return
+	}
+	if len(other.counts) > len(h.counts) {
+		counts := make([]int64, len(other.counts))
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+
+	if h.total == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	h.total += other.total
+	h.sum += other.sum
+}
+
+// Reset 清空直方图
+func (h *Histogram) Reset() {
+	*h = Histogram{}
+}
+
+// Count 返回样本数量
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Min 返回最小值
+func (h *Histogram) Min() time.Duration {
+	return h.min
+}
+
+// Max 返回最大值
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Mean 返回平均值
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.total)
+}
+
+// Percentile 返回百分位数（p取值0~100），结果为所在桶的上界，不超过最大值
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p / 100 * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			value := time.Duration(bucketUpperBound(i)) * time.Microsecond
+			if value > h.max {
+				value = h.max
+			}
+			if value < h.min {
+				value = h.min
+			}
+			return value
+		}
+	}
+	return h.max
+}
+
+// bucketIndex 返回值所在桶的下标：小于subBucketCount的值每个值一个桶，
+// 更大的值按最高有效位分组，每组subBucketHalf个桶
+func bucketIndex(v uint64) int {
+	if v < subBucketCount {
+		return int(v)
+	}
+	shift := bits.Len64(v) - subBucketBits
+	return subBucketCount + (shift-1)*subBucketHalf + int(v>>shift) - subBucketHalf
+}
+
+// bucketUpperBound 返回桶能表示的最大值
+func bucketUpperBound(index int) uint64 {
+	if index < subBucketCount {
+		return uint64(index)
+	}
+	shift := (index-subBucketCount)/subBucketHalf + 1
+	sub := uint64((index-subBucketCount)%subBucketHalf + subBucketHalf)
+	return (sub+1)<<shift - 1
+}
@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketIndex(t *testing.T) {
+	tests := []struct {
+		value uint64
+		index int
+		upper uint64
+	}{
+		{0, 0, 0},
+		{1, 1, 1},
+		{63, 63, 63},
+		{64, 64, 65},
+		{65, 64, 65},
+		{66, 65, 67},
+		{127, 95, 127},
+		{128, 96, 131},
+		{131, 96, 131},
+		{132, 97, 135},
+		{255, 127, 255},
+		{256, 128, 263},
+		{1000, 190, 1007},
+		{1 << 20, 512, 1<<20 + 1<<15 - 1},
+	}
+
+	for _, tt := range tests {
+		index := bucketIndex(tt.value)
+		if index != tt.index {
+			t.Errorf("bucketIndex(%d) = %d, 期望 %d", tt.value, index, tt.index)
+		}
+		if upper := bucketUpperBound(index); upper != tt.upper {
+			t.Errorf("bucketUpperBound(%d) = %d, 期望 %d", index, upper, tt.upper)
+		}
+	}
+}
+
+func TestBucketBoundsContinuous(t *testing.T) {
+	// 每个桶的上界加1应该是下一个桶的第一个值（最后一个桶的下标为1919），且桶的相对宽度不超过1/subBucketHalf
+	for index := 0; index < 1900; index++ {
+		upper := bucketUpperBound(index)
+		if got := bucketIndex(upper); got != index {
+			t.Fatalf("bucketIndex(bucketUpperBound(%d)) = %d", index, got)
+		}
+		if got := bucketIndex(upper + 1); got != index+1 {
+			t.Fatalf("bucketIndex(%d) = %d, 期望 %d", upper+1, got, index+1)
+		}
+		if index > 0 {
+			lower := bucketUpperBound(index-1) + 1
+			if width := upper - lower + 1; width > 1 && float64(width)/float64(lower) > 1.0/subBucketHalf {
+				t.Fatalf("桶 %d [%d, %d] 的相对误差过大", index, lower, upper)
+			}
+		}
+	}
+}
+
+func TestHistogramPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{"空直方图", nil, 50, 0},
+		{"单个样本", []time.Duration{5 * time.Millisecond}, 99, 5 * time.Millisecond},
+		{"不足1微秒时不低于最小值", []time.Duration{10, 20, 30, 40}, 50, 10},
+		{"微秒", []time.Duration{10 * time.Microsecond, 20 * time.Microsecond, 30 * time.Microsecond, 40 * time.Microsecond}, 50, 20 * time.Microsecond},
+		{"p0返回最小值", []time.Duration{10 * time.Microsecond, 40 * time.Microsecond}, 0, 10 * time.Microsecond},
+		{"p100不超过最大值", []time.Duration{10 * time.Microsecond, 1000 * time.Microsecond}, 100, 1000 * time.Microsecond},
+		{"桶的上界", []time.Duration{1000 * time.Microsecond, 1001 * time.Microsecond, 5 * time.Millisecond}, 50, 1007 * time.Microsecond},
+		{"负数按0记录", []time.Duration{-time.Second, 2 * time.Microsecond}, 50, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHistogram()
+			for _, v := range tt.values {
+				h.Record(v)
+			}
+			if got := h.Percentile(tt.p); got != tt.want {
+				t.Errorf("Percentile(%v) = %v, 期望 %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a, b := NewHistogram(), NewHistogram()
+	for i := 1; i <= 50; i++ {
+		a.Record(time.Duration(i) * time.Millisecond)
+	}
+	for i := 51; i <= 100; i++ {
+		b.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	merged := NewHistogram()
+	merged.Merge(a)
+	merged.Merge(b)
+	merged.Merge(NewHistogram())
+
+	if merged.Count() != 100 {
+		t.Errorf("Count() = %d, 期望 100", merged.Count())
+	}
+	if merged.Min() != time.Millisecond || merged.Max() != 100*time.Millisecond {
+		t.Errorf("Min()/Max() = %v/%v", merged.Min(), merged.Max())
+	}
+	if merged.Mean() != 50500*time.Microsecond {
+		t.Errorf("Mean() = %v, 期望 50.5ms", merged.Mean())
+	}
+	// 桶的相对误差约为3%
+	for _, p := range []float64{50, 90, 99} {
+		exact := time.Duration(p) * time.Millisecond
+		got := merged.Percentile(p)
+		if got < exact || float64(got-exact) > float64(exact)/subBucketHalf {
+			t.Errorf("Percentile(%v) = %v, 期望接近 %v", p, got, exact)
+		}
+	}
+
+	merged.Reset()
+	if merged.Count() != 0 || merged.Percentile(50) != 0 {
+		t.Errorf("Reset() 之后 Count() = %d", merged.Count())
+	}
+}
@@ -0,0 +1,210 @@
+package xpath
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound 表示XPath没有匹配到任何节点
+var ErrNotFound = errors.New("没有匹配的节点")
+
+// node XML元素节点
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     strings.Builder
+}
+
+// step XPath中的一步
+type step struct {
+	descendant bool   // 是否为//（匹配所有后代）
+	name       string // 元素名称、*、@属性名或text()
+	index      int    // [n]位置谓词（从1开始），0表示不限
+}
+
+// Lookup 按XPath表达式从XML文档中取值，返回第一个匹配节点的文本
+// 支持的语法：/a/b、//b、*、[n]、@attr、text()
+func Lookup(data []byte, expr string) (string, error) {
+	root, err := parse(data)
+	if err != nil {
+		return "", err
+	}
+
+	steps, err := compile(expr)
+	if err != nil {
+		return "", err
+	}
+
+	current := []*node{root}
+	for i, s := range steps {
+		last := i == len(steps)-1
+
+		// 最后一步可以是属性或文本
+		if last && strings.HasPrefix(s.name, "@") {
+			for _, n := range candidates(current, s.descendant) {
+				if value, ok := n.attrs[s.name[1:]]; ok {
+					return value, nil
+				}
+			}
+			return "", fmt.Errorf("%w: %s", ErrNotFound, expr)
+		}
+		if last && s.name == "text()" {
+			if len(current) == 0 {
+				break
+			}
+			return current[0].text.String(), nil
+		}
+
+		next := make([]*node, 0)
+		for _, parent := range current {
+			matched := make([]*node, 0)
+			for _, n := range children(parent, s.descendant) {
+				if s.name == "*" || n.name == s.name {
+					matched = append(matched, n)
+				}
+			}
+			if s.index > 0 {
+				if s.index <= len(matched) {
+					next = append(next, matched[s.index-1])
+				}
+				continue
+			}
+			next = append(next, matched...)
+		}
+		current = next
+	}
+
+	if len(current) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, expr)
+	}
+	return textContent(current[0]), nil
+}
+
+// parse 将XML文档解析为节点树，返回一个虚拟的文档根节点
+func parse(data []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	root := &node{}
+	stack := []*node{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("响应体不是有效的XML: %w", err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				n.attrs[attr.Name.Local] = attr.Value
+			}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.text.Write(t)
+		}
+	}
+
+	if len(root.children) == 0 {
+		return nil, fmt.Errorf("响应体不是有效的XML")
+	}
+	return root, nil
+}
+
+// compile 将XPath表达式拆分为步骤
+func compile(expr string) ([]step, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "/") {
+		return nil, fmt.Errorf("XPath必须以'/'开头: %s", expr)
+	}
+
+	steps := make([]step, 0)
+	rest := expr
+	for rest != "" {
+		s := step{}
+		if strings.HasPrefix(rest, "//") {
+			s.descendant = true
+			rest = rest[2:]
+		} else if strings.HasPrefix(rest, "/") {
+			rest = rest[1:]
+		} else {
+			return nil, fmt.Errorf("无效的XPath: %s", expr)
+		}
+
+		end := strings.Index(rest, "/")
+		if end < 0 {
+			end = len(rest)
+		}
+		part := rest[:end]
+		rest = rest[end:]
+
+		if idx := strings.Index(part, "["); idx >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("无效的XPath谓词: %s", part)
+			}
+			index, err := strconv.Atoi(part[idx+1 : len(part)-1])
+			if err != nil || index < 1 {
+				return nil, fmt.Errorf("只支持数字位置谓词: %s", part)
+			}
+			s.index = index
+			part = part[:idx]
+		}
+		if part == "" {
+			return nil, fmt.Errorf("无效的XPath: %s", expr)
+		}
+		s.name = part
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+// children 返回节点的子元素，descendant为true时返回所有后代元素
+func children(n *node, descendant bool) []*node {
+	if !descendant {
+		return n.children
+	}
+	result := make([]*node, 0)
+	for _, child := range n.children {
+		result = append(result, child)
+		result = append(result, children(child, true)...)
+	}
+	return result
+}
+
+// candidates 返回用于匹配属性的节点
+func candidates(nodes []*node, descendant bool) []*node {
+	if !descendant {
+		return nodes
+	}
+	result := make([]*node, 0)
+	for _, n := range nodes {
+		result = append(result, n)
+		result = append(result, children(n, true)...)
+	}
+	return result
+}
+
+// textContent 返回节点及其所有后代的文本
+func textContent(n *node) string {
+	var b strings.Builder
+	b.WriteString(n.text.String())
+	for _, child := range n.children {
+		b.WriteString(textContent(child))
+	}
+	return strings.TrimSpace(b.String())
+}
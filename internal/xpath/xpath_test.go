@@ -0,0 +1,86 @@
+package xpath
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<library name="城市图书馆">
+	<book id="1" lang="zh">
+		<title>三体</title>
+		<author>刘慈欣</author>
+	</book>
+	<book id="2">
+		<title>Go语言</title>
+		<author>Alan</author>
+		<author>Brian</author>
+	</book>
+	<magazine><title>读者</title></magazine>
+</library>`)
+
+	tests := []struct {
+		expr    string
+		want    string
+		wantErr error
+	}{
+		{expr: "/library/book/title", want: "三体"},
+		{expr: "/library/book[2]/title", want: "Go语言"},
+		{expr: "/library/book[2]/author[2]", want: "Brian"},
+		{expr: "//title", want: "三体"},
+		{expr: "//magazine/title", want: "读者"},
+		{expr: "/library/*[3]/title", want: "读者"},
+		{expr: "/library/@name", want: "城市图书馆"},
+		{expr: "/library/book[2]/@id", want: "2"},
+		{expr: "//@lang", want: "zh"},
+		{expr: "/library/book/title/text()", want: "三体"},
+		{expr: "/library/magazine", want: "读者"},
+		{expr: "/library/book[3]", wantErr: ErrNotFound},
+		{expr: "/library/dvd", wantErr: ErrNotFound},
+		{expr: "/library/@missing", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Lookup(data, tt.expr)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Lookup() 错误 = %v, 期望 %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup() 错误 = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Lookup() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		expr    string
+		wantErr string
+	}{
+		{"不是XML", `{"a": 1}`, "/a", "响应体不是有效的XML"},
+		{"缺少/", `<a/>`, "a", "XPath必须以'/'开头"},
+		{"非数字谓词", `<a/>`, "/a[@id]", "只支持数字位置谓词"},
+		{"未闭合的谓词", `<a/>`, "/a[1", "无效的XPath谓词"},
+		{"空的步骤", `<a/>`, "/a/", "无效的XPath"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Lookup([]byte(tt.data), tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Lookup() 错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}